@@ -0,0 +1,353 @@
+// Package adminapi serves local HTTP API of bot owner operations with health and readiness checks
+package adminapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/FlameInTheDark/dtbot/bot"
+)
+
+const (
+	// maxPageSize max count of logs or guilds in one response
+	maxPageSize = 1000
+	// defaultPageSize count of logs or guilds if limit is not set
+	defaultPageSize = 50
+)
+
+// API contains storages of bot owner operations, same as used by commands
+type API struct {
+	Conf      *bot.Config
+	Discord   *discordgo.Session
+	DB        *bot.DBWorker
+	Sessions  *bot.SessionManager
+	BlackList *bot.BlackListStruct
+
+	server *http.Server
+}
+
+// logItem log row in response
+type logItem struct {
+	Date   time.Time `json:"date"`
+	Module string    `json:"module"`
+	Guild  string    `json:"guild"`
+	Text   string    `json:"text"`
+}
+
+// guildItem guild in response
+type guildItem struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	OwnerID     string `json:"owner_id"`
+	Members     int    `json:"members"`
+	Blacklisted bool   `json:"blacklisted"`
+}
+
+// stationItem radio station in requests and responses
+type stationItem struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Category string `json:"category"`
+}
+
+// Start starts API server if listen address is set
+func (a *API) Start() {
+	if a.Conf.AdminAPI.Listen == "" {
+		return
+	}
+	if a.Conf.AdminAPI.Token == "" {
+		fmt.Println("Admin API is not started: token is empty")
+		return
+	}
+	if !loopback(a.Conf.AdminAPI.Listen) {
+		fmt.Printf("Admin API is not started: listen address %v is not loopback, use 127.0.0.1:port\n", a.Conf.AdminAPI.Listen)
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", a.handleHealth)
+	mux.HandleFunc("/ready", a.handleReady)
+	mux.HandleFunc("/api/stats", a.auth(a.handleStats))
+	mux.HandleFunc("/api/logs", a.auth(a.handleLogs))
+	mux.HandleFunc("/api/guilds", a.auth(a.handleGuilds))
+	mux.HandleFunc("/api/guilds/", a.auth(a.handleGuild))
+	mux.HandleFunc("/api/blacklist", a.auth(a.handleBlacklist))
+	mux.HandleFunc("/api/blacklist/", a.auth(a.handleBlacklistItem))
+	mux.HandleFunc("/api/stations", a.auth(a.handleStations))
+	mux.HandleFunc("/api/stations/", a.auth(a.handleStation))
+	a.server = &http.Server{Addr: a.Conf.AdminAPI.Listen, Handler: mux}
+	go func() {
+		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			a.DB.Log("AdminAPI", "", fmt.Sprintf("Admin API server error: %v", err))
+			fmt.Printf("Admin API server error: %v\n", err)
+		}
+	}()
+	fmt.Printf("Admin API started on %v\n", a.Conf.AdminAPI.Listen)
+}
+
+// loopback returns true if listen address has loopback host, API must not be reachable from network
+func loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// auth rejects requests without bearer token of config
+func (a *API) auth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.Conf.AdminAPI.Token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// writeJSON sends value as JSON
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError sends error as JSON
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
+
+// methodNotAllowed sends error of wrong request method
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// page returns offset and limit of request
+func page(r *http.Request) (int, int) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset, limit
+}
+
+// handleHealth returns OK while process is running
+func (a *API) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReady returns OK if Discord session and database are ready
+func (a *API) handleReady(w http.ResponseWriter, r *http.Request) {
+	var checks = map[string]string{"discord": "ok", "database": "ok"}
+	var code = http.StatusOK
+	if !a.Discord.DataReady {
+		checks["discord"] = "not ready"
+		code = http.StatusServiceUnavailable
+	}
+	if err := a.DB.Ping(); err != nil {
+		checks["database"] = err.Error()
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, checks)
+}
+
+// handleStats returns counts of guilds, users and voice sessions
+func (a *API) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w)
+		return
+	}
+	var users int
+	a.Discord.State.RLock()
+	guilds := len(a.Discord.State.Guilds)
+	for _, g := range a.Discord.State.Guilds {
+		users += g.MemberCount
+	}
+	a.Discord.State.RUnlock()
+	writeJSON(w, http.StatusOK, map[string]int{
+		"guilds": guilds,
+		"users":  users,
+		"voices": a.Sessions.Count(),
+	})
+}
+
+// handleLogs returns latest logs, newest first
+func (a *API) handleLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w)
+		return
+	}
+	offset, limit := page(r)
+	var logs = []logItem{}
+	for _, l := range a.DB.LogGetPage(offset, limit) {
+		logs = append(logs, logItem{Date: l.Date, Module: l.Module, Guild: l.Guild, Text: l.Text})
+	}
+	writeJSON(w, http.StatusOK, logs)
+}
+
+// handleGuilds returns guilds of bot sorted by name
+func (a *API) handleGuilds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w)
+		return
+	}
+	var guilds = []guildItem{}
+	a.Discord.State.RLock()
+	for _, g := range a.Discord.State.Guilds {
+		guilds = append(guilds, guildItem{ID: g.ID, Name: g.Name, OwnerID: g.OwnerID, Members: g.MemberCount})
+	}
+	a.Discord.State.RUnlock()
+	for i := range guilds {
+		guilds[i].Blacklisted = a.BlackList.CheckGuild(guilds[i].ID)
+	}
+	sort.Slice(guilds, func(i, j int) bool { return strings.ToLower(guilds[i].Name) < strings.ToLower(guilds[j].Name) })
+	offset, limit := page(r)
+	total := len(guilds)
+	if offset > total {
+		offset = total
+	}
+	if offset+limit < total {
+		guilds = guilds[offset : offset+limit]
+	} else {
+		guilds = guilds[offset:]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"total": total, "guilds": guilds})
+}
+
+// handleGuild handles POST /api/guilds/{id}/leave
+func (a *API) handleGuild(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/guilds/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "leave" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != "POST" {
+		methodNotAllowed(w)
+		return
+	}
+	if _, err := a.Discord.State.Guild(parts[0]); err != nil {
+		writeError(w, http.StatusNotFound, "guild not found")
+		return
+	}
+	if err := a.Discord.GuildLeave(parts[0]); err != nil {
+		a.DB.Log("AdminAPI", parts[0], fmt.Sprintf("error leaving from guild: %v", err))
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	a.DB.Log("AdminAPI", parts[0], "Left from guild")
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleBlacklist returns blacklisted guilds and users
+func (a *API) handleBlacklist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		methodNotAllowed(w)
+		return
+	}
+	guilds, users := a.BlackList.List()
+	writeJSON(w, http.StatusOK, map[string][]string{"guilds": guilds, "users": users})
+}
+
+// handleBlacklistItem adds (PUT) or removes (DELETE) /api/blacklist/{guilds|users}/{id}
+func (a *API) handleBlacklistItem(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/blacklist/"), "/")
+	if len(parts) != 2 || parts[1] == "" || (parts[0] != "guilds" && parts[0] != "users") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	id := parts[1]
+	switch r.Method {
+	case "PUT":
+		if parts[0] == "guilds" {
+			a.BlackList.AddGuild(a.DB, id)
+		} else {
+			a.BlackList.AddUser(a.DB, id)
+		}
+	case "DELETE":
+		if parts[0] == "guilds" {
+			a.BlackList.RemoveGuild(a.DB, id)
+		} else {
+			a.BlackList.RemoveUser(a.DB, id)
+		}
+	default:
+		methodNotAllowed(w)
+		return
+	}
+	a.DB.Log("AdminAPI", "", fmt.Sprintf("Blacklist %v %v: %v", r.Method, parts[0], id))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleStations returns (GET) or adds (POST) radio stations
+func (a *API) handleStations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		var stations = []stationItem{}
+		for _, s := range a.DB.GetRadioStations(r.URL.Query().Get("category")) {
+			stations = append(stations, stationItem{Key: s.Key, Name: s.Name, URL: s.URL, Category: s.Category})
+		}
+		writeJSON(w, http.StatusOK, stations)
+	case "POST":
+		var s stationItem
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&s); err != nil {
+			writeError(w, http.StatusBadRequest, "wrong JSON: "+err.Error())
+			return
+		}
+		if s.Key == "" || s.Name == "" || s.URL == "" || s.Category == "" {
+			writeError(w, http.StatusBadRequest, "key, name, url and category are required")
+			return
+		}
+		if _, err := a.DB.GetRadioStationByKey(s.Key); err == nil {
+			writeError(w, http.StatusConflict, "station already exists")
+			return
+		}
+		if err := a.DB.AddRadioStation(s.Name, s.URL, s.Key, s.Category); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		a.DB.Log("AdminAPI", "", fmt.Sprintf("Station added: %v", s.Key))
+		writeJSON(w, http.StatusCreated, s)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// handleStation removes (DELETE) /api/stations/{key}
+func (a *API) handleStation(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/api/stations/")
+	if key == "" || strings.Contains(key, "/") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != "DELETE" {
+		methodNotAllowed(w)
+		return
+	}
+	if err := a.DB.RemoveRadioStation(key); err != nil {
+		writeError(w, http.StatusNotFound, "station not found")
+		return
+	}
+	a.DB.Log("AdminAPI", "", fmt.Sprintf("Station removed: %v", key))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,185 @@
+// Package cache contains LRU cache of external API responses
+// with expiration time and optional persistent backend
+package cache
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultSize max count of entries in memory if size is not specified
+const DefaultSize = 1000
+
+// Entry contains cached value
+type Entry struct {
+	Provider string
+	Key      string
+	Value    []byte
+	Expires  time.Time
+}
+
+// Expired returns true if entry lifetime is over
+func (e *Entry) Expired() bool {
+	return time.Now().After(e.Expires)
+}
+
+// Backend persistent storage of entries, used when entry not found in memory
+type Backend interface {
+	Load(provider, key string) (*Entry, bool)
+	Save(entry *Entry)
+	// Flush removes entries of provider or all entries if provider is empty
+	Flush(provider string)
+}
+
+// Stats contains cache usage counters of provider
+type Stats struct {
+	Provider  string
+	Entries   int
+	Hits      int64
+	Misses    int64
+	Evictions int64
+}
+
+// Cache LRU cache with entries lifetime
+type Cache struct {
+	sync.Mutex
+	size    int
+	backend Backend
+	order   *list.List
+	entries map[string]*list.Element
+	stats   map[string]*Stats
+}
+
+// New creates cache with max size of entries in memory and persistent backend, backend can be nil
+func New(size int, backend Backend) *Cache {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Cache{
+		size:    size,
+		backend: backend,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		stats:   make(map[string]*Stats),
+	}
+}
+
+func entryKey(provider, key string) string {
+	return provider + ":" + key
+}
+
+// providerStats returns counters of provider, must be called with lock
+func (c *Cache) providerStats(provider string) *Stats {
+	s, ok := c.stats[provider]
+	if !ok {
+		s = &Stats{Provider: provider}
+		c.stats[provider] = s
+	}
+	return s
+}
+
+// Get returns cached value of provider by key
+func (c *Cache) Get(provider, key string) ([]byte, bool) {
+	c.Lock()
+	if el, ok := c.entries[entryKey(provider, key)]; ok {
+		entry := el.Value.(*Entry)
+		if !entry.Expired() {
+			c.order.MoveToFront(el)
+			c.providerStats(provider).Hits++
+			c.Unlock()
+			return entry.Value, true
+		}
+		c.remove(el)
+	}
+	c.Unlock()
+
+	if c.backend != nil {
+		if entry, ok := c.backend.Load(provider, key); ok && !entry.Expired() {
+			c.Lock()
+			c.add(entry)
+			c.providerStats(provider).Hits++
+			c.Unlock()
+			return entry.Value, true
+		}
+	}
+
+	c.Lock()
+	c.providerStats(provider).Misses++
+	c.Unlock()
+	return nil, false
+}
+
+// Set saves value of provider by key for ttl duration
+func (c *Cache) Set(provider, key string, value []byte, ttl time.Duration) {
+	entry := &Entry{Provider: provider, Key: key, Value: value, Expires: time.Now().Add(ttl)}
+	c.Lock()
+	c.add(entry)
+	c.Unlock()
+	if c.backend != nil {
+		c.backend.Save(entry)
+	}
+}
+
+// add puts entry in memory and evicts oldest entries, must be called with lock
+func (c *Cache) add(entry *Entry) {
+	k := entryKey(entry.Provider, entry.Key)
+	if el, ok := c.entries[k]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[k] = c.order.PushFront(entry)
+	c.providerStats(entry.Provider).Entries++
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.providerStats(oldest.Value.(*Entry).Provider).Evictions++
+		c.remove(oldest)
+	}
+}
+
+// remove deletes element from memory, must be called with lock
+func (c *Cache) remove(el *list.Element) {
+	entry := el.Value.(*Entry)
+	c.order.Remove(el)
+	delete(c.entries, entryKey(entry.Provider, entry.Key))
+	c.providerStats(entry.Provider).Entries--
+}
+
+// Flush removes entries of provider or all entries if provider is empty
+func (c *Cache) Flush(provider string) {
+	c.Lock()
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if provider == "" || el.Value.(*Entry).Provider == provider {
+			c.remove(el)
+		}
+		el = next
+	}
+	c.Unlock()
+	if c.backend != nil {
+		c.backend.Flush(provider)
+	}
+}
+
+// Stats returns counters of all providers sorted by name
+func (c *Cache) Stats() []Stats {
+	c.Lock()
+	defer c.Unlock()
+	var result []Stats
+	for _, s := range c.stats {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Provider < result[j].Provider
+	})
+	return result
+}
+
+// Len returns count of entries in memory
+func (c *Cache) Len() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.order.Len()
+}
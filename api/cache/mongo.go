@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// MongoBackend persistent backend stored in MongoDB collection,
+// expired documents are removed by TTL index
+type MongoBackend struct {
+	collection *mgo.Collection
+}
+
+// NewMongoBackend creates backend in specified collection
+func NewMongoBackend(collection *mgo.Collection) *MongoBackend {
+	_ = collection.EnsureIndex(mgo.Index{Key: []string{"expires"}, ExpireAfter: time.Second})
+	_ = collection.EnsureIndex(mgo.Index{Key: []string{"provider", "key"}, Unique: true})
+	return &MongoBackend{collection: collection}
+}
+
+// Load returns entry of provider by key
+func (b *MongoBackend) Load(provider, key string) (*Entry, bool) {
+	var entry Entry
+	err := b.collection.Find(bson.M{"provider": provider, "key": key}).One(&entry)
+	if err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Save saves entry in collection
+func (b *MongoBackend) Save(entry *Entry) {
+	_, _ = b.collection.Upsert(bson.M{"provider": entry.Provider, "key": entry.Key}, entry)
+}
+
+// Flush removes entries of provider or all entries if provider is empty
+func (b *MongoBackend) Flush(provider string) {
+	if provider == "" {
+		_, _ = b.collection.RemoveAll(nil)
+		return
+	}
+	_, _ = b.collection.RemoveAll(bson.M{"provider": provider})
+}
@@ -0,0 +1,147 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/bot"
+	"github.com/bwmarrin/discordgo"
+)
+
+// parseAlert parses arguments like "USD > 95" or "BTC USD < 50000"
+func parseAlert(args []string) (*bot.CurrencyAlert, error) {
+	var alert bot.CurrencyAlert
+	if len(args) != 3 && len(args) != 4 {
+		return nil, errors.New("wrong arguments")
+	}
+	alert.Currency = strings.ToUpper(args[0])
+	if len(args) == 4 {
+		alert.Target = strings.ToUpper(args[1])
+	}
+	alert.Operator = args[len(args)-2]
+	if alert.Operator != ">" && alert.Operator != "<" {
+		return nil, errors.New("wrong operator")
+	}
+	value, err := strconv.ParseFloat(strings.Replace(args[len(args)-1], ",", ".", 1), 64)
+	if err != nil || value <= 0 {
+		return nil, errors.New("wrong value")
+	}
+	alert.Value = value
+	return &alert, nil
+}
+
+// AlertCommand manages user alerts. Arguments: alert [list|remove N|CURRENCY [CURRENCY] >|< VALUE]
+func AlertCommand(ctx *bot.Context) string {
+	service := GetService(ctx.Conf)
+	switch strings.ToLower(ctx.Arg(1)) {
+	case "", "list":
+		ctx.MetricsCommand("currency", "alert_list")
+		alerts := ctx.DB.GetCurrencyAlerts(ctx.User.ID)
+		if len(alerts) == 0 {
+			return ctx.Loc("currency_alerts_empty")
+		}
+		var list string
+		for i, a := range alerts {
+			list += fmt.Sprintf("%v. `%v/%v %v %v`\n", i+1, a.Currency, a.Target, a.Operator, formatValue(a.Value))
+		}
+		return list
+	case "remove":
+		ctx.MetricsCommand("currency", "alert_remove")
+		alerts := ctx.DB.GetCurrencyAlerts(ctx.User.ID)
+		index, err := strconv.Atoi(ctx.Arg(2))
+		if err != nil || index < 1 || index > len(alerts) {
+			return ctx.Loc("currency_alert_not_found")
+		}
+		if err := ctx.DB.RemoveCurrencyAlert(&alerts[index-1]); err != nil {
+			return ctx.Loc("error")
+		}
+		return ctx.Loc("currency_alert_removed")
+	}
+
+	ctx.MetricsCommand("currency", "alert_add")
+	alert, err := parseAlert(ctx.Args[1:])
+	if err != nil {
+		return ctx.Loc("currency_alert_usage")
+	}
+	if alert.Target == "" {
+		_, rates, err := service.Find(ctx.CallContext(), alert.Currency)
+		if err != nil {
+			return ctx.Loc("currency_unknown")
+		}
+		alert.Target = rates.Base
+	}
+	rate, err := service.Rate(ctx.CallContext(), alert.Currency, alert.Target)
+	if err != nil {
+		return ctx.Loc("currency_unknown")
+	}
+
+	limit := ctx.Conf.Currency.AlertsLimit
+	if limit == 0 {
+		limit = 10
+	}
+	if len(ctx.DB.GetCurrencyAlerts(ctx.User.ID)) >= limit {
+		return fmt.Sprintf(ctx.Loc("currency_alerts_limit"), limit)
+	}
+
+	alert.UserID = ctx.User.ID
+	alert.Language = ctx.GetGuild().Language
+	// Alert fires only when rate crosses threshold, not when it is already beyond it
+	alert.Triggered = alert.Check(rate)
+	if err := ctx.DB.AddCurrencyAlert(alert); err != nil {
+		return ctx.Loc("error")
+	}
+	return fmt.Sprintf(ctx.Loc("currency_alert_added"),
+		alert.Currency, alert.Target, alert.Operator, formatValue(alert.Value), formatValue(rate))
+}
+
+// CheckAlerts checks rates and sends personal messages for crossed thresholds
+func CheckAlerts(session *discordgo.Session, db *bot.DBWorker, conf *bot.Config) {
+	service := GetService(conf)
+	cooldown := time.Duration(conf.Currency.AlertCooldown) * time.Minute
+	if cooldown == 0 {
+		cooldown = time.Hour
+	}
+	alerts := db.GetCurrencyAlerts("")
+	for i := range alerts {
+		alert := &alerts[i]
+		rate, err := service.Rate(context.Background(), alert.Currency, alert.Target)
+		if err != nil {
+			continue
+		}
+		beyond := alert.Check(rate)
+		if beyond == alert.Triggered {
+			continue
+		}
+		// Crossing during cooldown stays pending, alert is sent by first check after cooldown
+		if beyond && time.Since(time.Unix(alert.LastFired, 0)) <= cooldown {
+			continue
+		}
+		alert.Triggered = beyond
+		if beyond {
+			alert.LastFired = time.Now().Unix()
+			sendAlert(session, conf, alert, rate)
+		}
+		db.UpdateCurrencyAlert(alert)
+	}
+}
+
+// sendAlert sends alert to user
+func sendAlert(session *discordgo.Session, conf *bot.Config, alert *bot.CurrencyAlert, rate float64) {
+	embed := bot.NewEmbed(conf.GetLocaleLang("currency", alert.Language)).
+		Desc(fmt.Sprintf(conf.GetLocaleLang("currency_alert_fired", alert.Language),
+			alert.Currency, alert.Target, alert.Operator, formatValue(alert.Value), formatValue(rate))).
+		Color(conf.General.EmbedColor)
+	ch, err := session.UserChannelCreate(alert.UserID)
+	if err != nil {
+		fmt.Println("Error whilst creating private channel, ", err.Error())
+		return
+	}
+	_, err = session.ChannelMessageSendEmbed(ch.ID, embed.GetEmbed())
+	if err != nil {
+		fmt.Println("Error whilst sending embed message, ", err.Error())
+	}
+}
@@ -0,0 +1,126 @@
+package currency
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+// CBR rates of the Central Bank of Russia
+type CBR struct {
+	// mu guards ids, rates are requested by concurrent commands
+	mu sync.RWMutex
+	// ids contains internal CBR ids of currencies used in history requests
+	ids map[string]string
+}
+
+type cbrHistory struct {
+	Records []struct {
+		Date    string `xml:"Date,attr"`
+		Nominal string `xml:"Nominal"`
+		Value   string `xml:"Value"`
+	} `xml:"Record"`
+}
+
+// Name returns provider name
+func (c *CBR) Name() string {
+	return "cbr"
+}
+
+// TTL returns time to keep rates in cache
+func (c *CBR) TTL() time.Duration {
+	return time.Hour
+}
+
+// Rates returns current rates in RUB
+func (c *CBR) Rates(ctx context.Context) (*Rates, error) {
+	var data Data
+	err := httpclient.For("cbr").GetJSON(ctx, "https://www.cbr-xml-daily.ru/daily_json.js", &data)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := &Rates{
+		Base:     "RUB",
+		Values:   make(map[string]float64),
+		Previous: make(map[string]float64),
+		Names:    make(map[string]string),
+	}
+	rates.Date, _ = time.Parse(time.RFC3339, data.Date)
+	ids := make(map[string]string)
+	for code, cur := range data.Currencies {
+		if cur.Nominal == 0 {
+			continue
+		}
+		rates.Values[code] = float64(cur.Value) / float64(cur.Nominal)
+		rates.Previous[code] = float64(cur.Previous) / float64(cur.Nominal)
+		rates.Names[code] = cur.Name
+		ids[code] = cur.ID
+	}
+	c.mu.Lock()
+	c.ids = ids
+	c.mu.Unlock()
+	return rates, nil
+}
+
+// History returns values of currency in RUB for last days
+func (c *CBR) History(ctx context.Context, code string, days int) ([]HistoryPoint, error) {
+	c.mu.RLock()
+	loaded := c.ids != nil
+	c.mu.RUnlock()
+	if !loaded {
+		if _, err := c.Rates(ctx); err != nil {
+			return nil, err
+		}
+	}
+	c.mu.RLock()
+	id, ok := c.ids[code]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownCurrency
+	}
+
+	now := time.Now()
+	body, err := httpclient.For("cbr").Get(ctx, fmt.Sprintf("https://www.cbr.ru/scripts/XML_dynamic.asp?date_req1=%v&date_req2=%v&VAL_NM_RQ=%v",
+		now.AddDate(0, 0, -days).Format("02/01/2006"), now.Format("02/01/2006"), id))
+	if err != nil {
+		return nil, err
+	}
+
+	var history cbrHistory
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	// Response is in windows-1251, but records contain only ASCII symbols
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	err = decoder.Decode(&history)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []HistoryPoint
+	for _, r := range history.Records {
+		date, err := time.Parse("02.01.2006", r.Date)
+		if err != nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.Replace(r.Value, ",", ".", 1), 64)
+		if err != nil {
+			continue
+		}
+		nominal, err := strconv.ParseFloat(r.Nominal, 64)
+		if err != nil || nominal == 0 {
+			nominal = 1
+		}
+		points = append(points, HistoryPoint{Date: date, Value: value / nominal})
+	}
+	return points, nil
+}
@@ -0,0 +1,133 @@
+package currency
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/FlameInTheDark/dtbot/bot"
+	"github.com/fogleman/gg"
+)
+
+// maxChartDays maximum period of chart
+const maxChartDays = 365
+
+// parsePeriod parses period like "30d", "2w", "6m" or "1y" and returns count of days
+func parsePeriod(period string) (int, error) {
+	if len(period) < 2 {
+		return 0, errors.New("wrong period")
+	}
+	count, err := strconv.Atoi(period[:len(period)-1])
+	if err != nil || count <= 0 {
+		return 0, errors.New("wrong period")
+	}
+	switch strings.ToLower(period[len(period)-1:]) {
+	case "d":
+		return count, nil
+	case "w":
+		return count * 7, nil
+	case "m":
+		return count * 30, nil
+	case "y":
+		return count * 365, nil
+	}
+	return 0, errors.New("wrong period")
+}
+
+// GetChart returns image with historical rate graph. Arguments: chart CURRENCY [CURRENCY] [period]
+func GetChart(ctx *bot.Context) (buf *bytes.Buffer, err error) {
+	var (
+		from, to string
+		days     = 30
+	)
+	for _, arg := range ctx.Args[1:] {
+		if d, perr := parsePeriod(arg); perr == nil {
+			days = d
+			continue
+		}
+		if from == "" {
+			from = strings.ToUpper(arg)
+		} else {
+			to = strings.ToUpper(arg)
+		}
+	}
+	if from == "" {
+		return nil, errors.New("currency is not specified")
+	}
+	if days > maxChartDays {
+		days = maxChartDays
+	}
+
+	points, base, err := GetService(ctx.Conf).History(ctx.CallContext(), from, to, days)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) < 2 {
+		return nil, errors.New("not enough data")
+	}
+
+	const (
+		width   = 600
+		height  = 300
+		padding = 50
+	)
+	min, max := points[0].Value, points[0].Value
+	for _, p := range points {
+		if p.Value < min {
+			min = p.Value
+		}
+		if p.Value > max {
+			max = p.Value
+		}
+	}
+	if max == min {
+		max = min + 1
+	}
+
+	gc := gg.NewContext(width, height)
+	gc.SetRGB255(47, 49, 54)
+	gc.DrawRoundedRectangle(0, 0, width, height, 10)
+	gc.Fill()
+
+	if err := gc.LoadFontFace("lato.ttf", 16); err != nil {
+		return nil, err
+	}
+
+	// Grid and labels
+	gc.SetLineWidth(1)
+	for i := 0; i <= 4; i++ {
+		y := padding + float64(height-padding*2)*float64(i)/4
+		gc.SetRGBA(1, 1, 1, 0.1)
+		gc.DrawLine(padding, y, width-padding/2, y)
+		gc.Stroke()
+		gc.SetRGBA(1, 1, 1, 0.5)
+		gc.DrawStringAnchored(formatValue(max-(max-min)*float64(i)/4), padding-5, y, 1, 0.5)
+	}
+
+	gc.SetRGBA(1, 1, 1, 0.8)
+	gc.DrawStringAnchored(fmt.Sprintf("%v/%v %vd", from, base, days), padding, padding/2, 0, 0.5)
+	gc.SetRGBA(1, 1, 1, 0.5)
+	gc.DrawStringAnchored(points[0].Date.Format("02.01.2006"), padding, height-padding/2, 0, 0.5)
+	gc.DrawStringAnchored(points[len(points)-1].Date.Format("02.01.2006"), width-padding/2, height-padding/2, 1, 0.5)
+
+	// Rate line
+	start, end := points[0].Date.Unix(), points[len(points)-1].Date.Unix()
+	if end == start {
+		end = start + 1
+	}
+	gc.SetRGB255(242, 97, 73)
+	gc.SetLineWidth(2)
+	for _, p := range points {
+		x := padding + float64(width-padding*3/2)*float64(p.Date.Unix()-start)/float64(end-start)
+		y := padding + float64(height-padding*2)*(max-p.Value)/(max-min)
+		gc.LineTo(x, y)
+	}
+	gc.Stroke()
+
+	buf = new(bytes.Buffer)
+	err = png.Encode(buf, gc.Image())
+	return buf, err
+}
@@ -0,0 +1,35 @@
+package currency
+
+import "testing"
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		period string
+		days   int
+		err    bool
+	}{
+		{"30d", 30, false},
+		{"2w", 14, false},
+		{"6m", 180, false},
+		{"1y", 365, false},
+		{"3D", 3, false},
+		{"d", 0, true},
+		{"0d", 0, true},
+		{"-1d", 0, true},
+		{"10", 0, true},
+		{"5h", 0, true},
+		{"USD", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			days, err := parsePeriod(tt.period)
+			if (err != nil) != tt.err {
+				t.Fatalf("error = %v, want error %v", err, tt.err)
+			}
+			if days != tt.days {
+				t.Errorf("parsePeriod(%q) = %v, want %v", tt.period, days, tt.days)
+			}
+		})
+	}
+}
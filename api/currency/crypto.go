@@ -0,0 +1,100 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+// CryptoCoins maps cryptocurrency codes to CoinGecko coin ids
+var CryptoCoins = map[string]string{
+	"BTC":  "bitcoin",
+	"ETH":  "ethereum",
+	"LTC":  "litecoin",
+	"XRP":  "ripple",
+	"BCH":  "bitcoin-cash",
+	"DOGE": "dogecoin",
+	"USDT": "tether",
+	"BNB":  "binancecoin",
+	"SOL":  "solana",
+	"ADA":  "cardano",
+	"TON":  "the-open-network",
+	"TRX":  "tron",
+}
+
+// Crypto cryptocurrency prices from CoinGecko in USD
+type Crypto struct{}
+
+type cryptoChart struct {
+	Prices [][]float64 `json:"prices"`
+}
+
+// Name returns provider name
+func (c *Crypto) Name() string {
+	return "crypto"
+}
+
+// TTL returns time to keep rates in cache
+func (c *Crypto) TTL() time.Duration {
+	return time.Minute * 5
+}
+
+// Rates returns current prices in USD
+func (c *Crypto) Rates(ctx context.Context) (*Rates, error) {
+	var (
+		ids    []string
+		result map[string]map[string]float64
+	)
+	for _, id := range CryptoCoins {
+		ids = append(ids, id)
+	}
+	err := httpclient.For("coingecko").GetJSON(ctx, fmt.Sprintf("https://api.coingecko.com/api/v3/simple/price?ids=%v&vs_currencies=usd&include_24hr_change=true",
+		url.QueryEscape(strings.Join(ids, ","))), &result)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := &Rates{
+		Base:     "USD",
+		Date:     time.Now(),
+		Values:   make(map[string]float64),
+		Previous: make(map[string]float64),
+		Names:    make(map[string]string),
+	}
+	for code, id := range CryptoCoins {
+		price, ok := result[id]
+		if !ok || price["usd"] == 0 {
+			continue
+		}
+		rates.Values[code] = price["usd"]
+		rates.Previous[code] = price["usd"] / (1 + price["usd_24h_change"]/100)
+		rates.Names[code] = id
+	}
+	return rates, nil
+}
+
+// History returns prices of cryptocurrency in USD for last days
+func (c *Crypto) History(ctx context.Context, code string, days int) ([]HistoryPoint, error) {
+	id, ok := CryptoCoins[code]
+	if !ok {
+		return nil, ErrUnknownCurrency
+	}
+	var chart cryptoChart
+	err := httpclient.For("coingecko").GetJSON(ctx, fmt.Sprintf("https://api.coingecko.com/api/v3/coins/%v/market_chart?vs_currency=usd&interval=daily&days=%v", id, days), &chart)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []HistoryPoint
+	for _, p := range chart.Prices {
+		if len(p) < 2 {
+			continue
+		}
+		points = append(points, HistoryPoint{Date: time.Unix(int64(p[0])/1000, 0), Value: p[1]})
+	}
+	return points, nil
+}
@@ -1,11 +1,11 @@
 package currency
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/FlameInTheDark/dtbot/bot"
 )
@@ -19,19 +19,6 @@ type Data struct {
 	Currencies   map[string]Currency `json:"Valute"`
 }
 
-// CurrencyCheck returns true if currency is real
-func (d *Data) CurrencyCheck(currency string) bool {
-	if currency == "RUB" {
-		return true
-	}
-
-	if d.Currencies[currency].Value > 0 {
-		return true
-	}
-
-	return false
-}
-
 // Currency structure
 type Currency struct {
 	ID       string
@@ -43,89 +30,125 @@ type Currency struct {
 	Previous float32
 }
 
+var (
+	service     *Service
+	serviceOnce sync.Once
+)
+
+// GetService returns currency service with providers from config
+func GetService(conf *bot.Config) *Service {
+	serviceOnce.Do(func() {
+		var providers []Provider
+		names := conf.Currency.Providers
+		if len(names) == 0 {
+			names = []string{"cbr", "ecb", "crypto"}
+		}
+		for _, name := range names {
+			switch name {
+			case "cbr":
+				providers = append(providers, &CBR{})
+			case "ecb":
+				providers = append(providers, &ECB{})
+			case "crypto":
+				providers = append(providers, &Crypto{})
+			default:
+				fmt.Printf("Unknown currency provider \"%v\"\n", name)
+			}
+		}
+		service = NewService(providers...)
+	})
+	return service
+}
+
+// formatValue formats value with precision depending on its size
+func formatValue(value float64) string {
+	switch {
+	case value >= 100:
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	case value >= 1:
+		return strconv.FormatFloat(value, 'f', 4, 64)
+	default:
+		return strconv.FormatFloat(value, 'f', 6, 64)
+	}
+}
+
+// parseConversion parses arguments like "100 USD EUR", "USD EUR 100" or "100 usd to eur"
+func parseConversion(args []string) (amount float64, from, to string, err error) {
+	var codes []string
+	amount = 1
+	for _, arg := range args {
+		if strings.EqualFold(arg, "to") || strings.EqualFold(arg, "in") {
+			continue
+		}
+		if value, perr := strconv.ParseFloat(strings.Replace(arg, ",", ".", 1), 64); perr == nil {
+			amount = value
+			continue
+		}
+		codes = append(codes, strings.ToUpper(arg))
+	}
+	if len(codes) != 2 {
+		return 0, "", "", errors.New("wrong arguments")
+	}
+	return amount, codes[0], codes[1], nil
+}
+
 // GetCurrency returns string of parsed currency data
 func GetCurrency(ctx *bot.Context) (response string) {
 	var (
-		newData Data
 		args    = ctx.Conf.Currency.Default
+		service = GetService(ctx.Conf)
 	)
 
 	if len(ctx.Args) > 0 {
 		args = ctx.Args
 	}
 
-	resp, err := http.Get("https://www.cbr-xml-daily.ru/daily_json.js")
-	if err != nil {
-		response = fmt.Sprintf("API error: %v", err)
-		return
-	}
-
-	bbytes, berr := ioutil.ReadAll(resp.Body)
-	if berr != nil {
-		response = fmt.Sprintf("%v: %v", ctx.Loc("curr_resp_read_err"), berr)
-		return
-	}
-
-	jerr := json.Unmarshal(bbytes, &newData)
-	if jerr != nil {
-		response = fmt.Sprintf("%v: %v", ctx.Loc("curr_resp_parse_err"), jerr)
-		return
-	}
-
-	newData.Currencies["RUB"] = Currency{"R00000", "000", "RUB", 1, "Российский Рубль", 1, 1}
-
-	response = ""
-
 	// List of currencies
-	if args[0] == "list" {
+	if strings.ToLower(args[0]) == "list" {
 		ctx.MetricsCommand("currency", "list")
-		response = fmt.Sprintf("%v: ", ctx.Loc("available_currencies"))
-		for key := range newData.Currencies {
-			response = fmt.Sprintf("%v %v", response, key)
-		}
-		return
+		return fmt.Sprintf("%v: %v", ctx.Loc("available_currencies"), strings.Join(service.Codes(ctx.CallContext()), " "))
 	}
 
-	// TODO: i should complete currency converter
 	// Converting currencies
-	if len(args) > 3 && args[0] == "conv" {
+	if strings.ToLower(args[0]) == "conv" {
 		ctx.MetricsCommand("currency", "conv")
-		count, err := strconv.ParseFloat(args[3], 64)
+		amount, from, to, err := parseConversion(args[1:])
 		if err != nil {
-			response = fmt.Sprintf("%v: %v", ctx.Loc("error"), ctx.Loc("nan"))
-			return
+			return ctx.Loc("currency_conv_usage")
 		}
-		if c1, ok1 := newData.Currencies[args[1]]; ok1 {
-			if c2, ok2 := newData.Currencies[args[2]]; ok2 {
-				cur1 := c1.Value / float32(c1.Nominal)
-				cur2 := c2.Value / float32(c2.Nominal)
-				//cur1Delta := 1 / cur1
-				cur2Delta := 1 / cur2
-				res := cur2Delta * (cur1 * float32(count))
-				response = fmt.Sprintf("`%v %v = %0.2f %v`\n", args[3], args[1], res, args[2])
-			}
+		res, err := service.Convert(ctx.CallContext(), amount, from, to)
+		if err != nil {
+			return fmt.Sprintf("%v: %v", ctx.Loc("error"), ctx.Loc("currency_unknown"))
 		}
-		return
+		return fmt.Sprintf("`%v %v = %v %v`\n", formatValue(amount), from, formatValue(res), to)
 	}
+
 	ctx.MetricsCommand("currency", "main")
 	var arrow string
 	// Current currency
 	for _, arg := range args {
-		if newData.Currencies[arg].Value > 0 {
-			if newData.Currencies[arg].Value > newData.Currencies[arg].Previous {
-				arrow = "▲"
-			} else {
-				arrow = "▼"
-			}
-			response = fmt.Sprintf("%v%v\n`%v %v = %v RUB %v  %0.2v`\n",
-				response,
-				newData.Currencies[arg].Name,
-				newData.Currencies[arg].Nominal,
-				newData.Currencies[arg].CharCode,
-				newData.Currencies[arg].Value,
-				arrow,
-				newData.Currencies[arg].Value-newData.Currencies[arg].Previous)
+		code := strings.ToUpper(arg)
+		_, rates, err := service.Find(ctx.CallContext(), code)
+		if err != nil || code == rates.Base {
+			continue
 		}
+		value, previous := rates.Value(code), rates.Previous[code]
+		if value > previous {
+			arrow = "▲"
+		} else {
+			arrow = "▼"
+		}
+		response = fmt.Sprintf("%v%v\n`1 %v = %v %v %v  %v`\n",
+			response,
+			rates.Names[code],
+			code,
+			formatValue(value),
+			rates.Base,
+			arrow,
+			formatValue(value-previous))
+	}
+	if response == "" {
+		return ctx.Loc("currency_unknown")
 	}
 	return
 }
@@ -0,0 +1,35 @@
+package currency
+
+import "testing"
+
+func TestParseConversion(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		amount float64
+		from   string
+		to     string
+		err    bool
+	}{
+		{"amount first", []string{"100", "USD", "EUR"}, 100, "USD", "EUR", false},
+		{"amount last", []string{"USD", "EUR", "100"}, 100, "USD", "EUR", false},
+		{"lower case with to", []string{"100", "usd", "to", "eur"}, 100, "USD", "EUR", false},
+		{"in", []string{"5", "eur", "IN", "rub"}, 5, "EUR", "RUB", false},
+		{"decimal comma", []string{"2,5", "USD", "EUR"}, 2.5, "USD", "EUR", false},
+		{"default amount", []string{"USD", "EUR"}, 1, "USD", "EUR", false},
+		{"one currency", []string{"100", "USD"}, 0, "", "", true},
+		{"three currencies", []string{"USD", "EUR", "RUB"}, 0, "", "", true},
+		{"empty", nil, 0, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, from, to, err := parseConversion(tt.args)
+			if (err != nil) != tt.err {
+				t.Fatalf("error = %v, want error %v", err, tt.err)
+			}
+			if amount != tt.amount || from != tt.from || to != tt.to {
+				t.Errorf("parseConversion(%q) = %v %v %v, want %v %v %v", tt.args, amount, from, to, tt.amount, tt.from, tt.to)
+			}
+		})
+	}
+}
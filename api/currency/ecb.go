@@ -0,0 +1,99 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+// ECB euro foreign exchange reference rates of the European Central Bank
+type ECB struct{}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// Name returns provider name
+func (e *ECB) Name() string {
+	return "ecb"
+}
+
+// TTL returns time to keep rates in cache
+func (e *ECB) TTL() time.Duration {
+	return time.Hour
+}
+
+// getEnvelope returns rates of last 90 days. Days are sorted from newest to oldest
+func (e *ECB) getEnvelope(ctx context.Context) (*ecbEnvelope, error) {
+	var envelope ecbEnvelope
+	err := httpclient.For("ecb").GetXML(ctx, "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist-90d.xml", &envelope)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope.Days) == 0 {
+		return nil, errors.New("empty response")
+	}
+	return &envelope, nil
+}
+
+// Rates returns current rates in EUR
+func (e *ECB) Rates(ctx context.Context) (*Rates, error) {
+	envelope, err := e.getEnvelope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rates := &Rates{
+		Base:     "EUR",
+		Values:   make(map[string]float64),
+		Previous: make(map[string]float64),
+		Names:    make(map[string]string),
+	}
+	rates.Date, _ = time.Parse("2006-01-02", envelope.Days[0].Time)
+	// ECB publishes how many units of currency costs one euro
+	for _, r := range envelope.Days[0].Rates {
+		if r.Rate > 0 {
+			rates.Values[r.Currency] = 1 / r.Rate
+		}
+	}
+	if len(envelope.Days) > 1 {
+		for _, r := range envelope.Days[1].Rates {
+			if r.Rate > 0 {
+				rates.Previous[r.Currency] = 1 / r.Rate
+			}
+		}
+	}
+	return rates, nil
+}
+
+// History returns values of currency in EUR for last days (90 days maximum)
+func (e *ECB) History(ctx context.Context, code string, days int) ([]HistoryPoint, error) {
+	envelope, err := e.getEnvelope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from := time.Now().AddDate(0, 0, -days)
+	var points []HistoryPoint
+	for _, d := range envelope.Days {
+		date, err := time.Parse("2006-01-02", d.Time)
+		if err != nil || date.Before(from) {
+			continue
+		}
+		for _, r := range d.Rates {
+			if r.Currency == code && r.Rate > 0 {
+				points = append(points, HistoryPoint{Date: date, Value: 1 / r.Rate})
+			}
+		}
+	}
+	if len(points) == 0 {
+		return nil, ErrUnknownCurrency
+	}
+	return points, nil
+}
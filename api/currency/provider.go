@@ -0,0 +1,264 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownCurrency returns if no one provider has currency
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Rates contains rates of currencies in base currency
+type Rates struct {
+	Base string
+	Date time.Time
+	// Values amount of base currency for one unit of currency
+	Values map[string]float64
+	// Previous values from previous date
+	Previous map[string]float64
+	// Names full names of currencies
+	Names map[string]string
+}
+
+// Has returns true if rates contain currency
+func (r *Rates) Has(code string) bool {
+	if code == r.Base {
+		return true
+	}
+	_, ok := r.Values[code]
+	return ok
+}
+
+// Value returns amount of base currency for one unit of currency
+func (r *Rates) Value(code string) float64 {
+	if code == r.Base {
+		return 1
+	}
+	return r.Values[code]
+}
+
+// HistoryPoint contains currency value in base currency at date
+type HistoryPoint struct {
+	Date  time.Time
+	Value float64
+}
+
+// Provider source of currency rates
+type Provider interface {
+	// Name returns provider name
+	Name() string
+	// TTL returns time to keep rates in cache
+	TTL() time.Duration
+	// Rates returns current rates
+	Rates(ctx context.Context) (*Rates, error)
+	// History returns values of currency in provider base currency for last days
+	History(ctx context.Context, code string, days int) ([]HistoryPoint, error)
+}
+
+type cachedRates struct {
+	rates   *Rates
+	expires time.Time
+}
+
+type cachedHistory struct {
+	points  []HistoryPoint
+	expires time.Time
+}
+
+// Service requests rates from providers and caches them
+type Service struct {
+	sync.Mutex
+	Providers []Provider
+	rates     map[string]cachedRates
+	history   map[string]cachedHistory
+}
+
+// NewService creates service with specified providers. Providers order is priority of providers
+func NewService(providers ...Provider) *Service {
+	return &Service{
+		Providers: providers,
+		rates:     make(map[string]cachedRates),
+		history:   make(map[string]cachedHistory),
+	}
+}
+
+// Rates returns cached rates of provider
+func (s *Service) Rates(ctx context.Context, p Provider) (*Rates, error) {
+	s.Lock()
+	cached, ok := s.rates[p.Name()]
+	s.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.rates, nil
+	}
+	rates, err := p.Rates(ctx)
+	if err != nil {
+		// Outdated rates are better than nothing
+		if ok {
+			return cached.rates, nil
+		}
+		return nil, err
+	}
+	s.Lock()
+	s.rates[p.Name()] = cachedRates{rates: rates, expires: time.Now().Add(p.TTL())}
+	s.Unlock()
+	return rates, nil
+}
+
+// AllRates returns rates of all providers which are available
+func (s *Service) AllRates(ctx context.Context) []*Rates {
+	var list []*Rates
+	for _, p := range s.Providers {
+		rates, err := s.Rates(ctx, p)
+		if err != nil {
+			fmt.Printf("Currency provider %v error: %v\n", p.Name(), err.Error())
+			continue
+		}
+		list = append(list, rates)
+	}
+	return list
+}
+
+// Find returns first provider and it rates which contains currency
+func (s *Service) Find(ctx context.Context, code string) (Provider, *Rates, error) {
+	code = strings.ToUpper(code)
+	for _, p := range s.Providers {
+		rates, err := s.Rates(ctx, p)
+		if err != nil {
+			continue
+		}
+		if rates.Has(code) {
+			return p, rates, nil
+		}
+	}
+	return nil, nil, ErrUnknownCurrency
+}
+
+// Codes returns sorted list of all available currencies
+func (s *Service) Codes(ctx context.Context) []string {
+	var (
+		codes []string
+		added = make(map[string]bool)
+	)
+	for _, r := range s.AllRates(ctx) {
+		if !added[r.Base] {
+			added[r.Base] = true
+			codes = append(codes, r.Base)
+		}
+		for code := range r.Values {
+			if !added[code] {
+				added[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Rate returns how many units of currency "to" costs one unit of currency "from"
+func (s *Service) Rate(ctx context.Context, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	all := s.AllRates(ctx)
+	// Both currencies from same provider
+	for _, r := range all {
+		if r.Has(from) && r.Has(to) && r.Value(to) > 0 {
+			return r.Value(from) / r.Value(to), nil
+		}
+	}
+	// Cross rate through base currency of first provider
+	for _, a := range all {
+		if !a.Has(from) {
+			continue
+		}
+		for _, b := range all {
+			if b.Has(to) && b.Has(a.Base) && b.Value(to) > 0 {
+				return a.Value(from) * b.Value(a.Base) / b.Value(to), nil
+			}
+		}
+	}
+	return 0, ErrUnknownCurrency
+}
+
+// Convert converts amount of currency "from" to currency "to"
+func (s *Service) Convert(ctx context.Context, amount float64, from, to string) (float64, error) {
+	rate, err := s.Rate(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// History returns values of currency "from" in currency "to" for last days.
+// If "to" is empty, values returns in base currency of provider
+func (s *Service) History(ctx context.Context, from, to string, days int) (points []HistoryPoint, base string, err error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	p, rates, err := s.Find(ctx, from)
+	if err != nil {
+		return nil, "", err
+	}
+	if to == "" {
+		to = rates.Base
+	}
+	if to != rates.Base && !rates.Has(to) {
+		return nil, "", ErrUnknownCurrency
+	}
+
+	fromPoints, err := s.providerHistory(ctx, p, from, days)
+	if err != nil {
+		return nil, "", err
+	}
+	if to == rates.Base {
+		return fromPoints, to, nil
+	}
+
+	toPoints, err := s.providerHistory(ctx, p, to, days)
+	if err != nil {
+		return nil, "", err
+	}
+	var toValues = make(map[string]float64)
+	for _, tp := range toPoints {
+		toValues[tp.Date.Format("2006-01-02")] = tp.Value
+	}
+	for _, fp := range fromPoints {
+		if v, ok := toValues[fp.Date.Format("2006-01-02")]; ok && v > 0 {
+			points = append(points, HistoryPoint{Date: fp.Date, Value: fp.Value / v})
+		}
+	}
+	return points, to, nil
+}
+
+func (s *Service) providerHistory(ctx context.Context, p Provider, code string, days int) ([]HistoryPoint, error) {
+	if code == "" {
+		return nil, ErrUnknownCurrency
+	}
+	key := fmt.Sprintf("%v|%v|%v", p.Name(), code, days)
+	s.Lock()
+	cached, ok := s.history[key]
+	s.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.points, nil
+	}
+	var points []HistoryPoint
+	rates, err := s.Rates(ctx, p)
+	if err == nil && code == rates.Base {
+		// Base currency always costs one
+		for i := days; i >= 0; i-- {
+			points = append(points, HistoryPoint{Date: time.Now().AddDate(0, 0, -i), Value: 1})
+		}
+	} else {
+		points, err = p.History(ctx, code, days)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+	s.Lock()
+	s.history[key] = cachedHistory{points: points, expires: time.Now().Add(time.Hour)}
+	s.Unlock()
+	return points, nil
+}
@@ -2,13 +2,11 @@ package darksky
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
-	"github.com/FlameInTheDark/dtbot/api/location"
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 	"github.com/FlameInTheDark/dtbot/bot"
 	"github.com/fogleman/gg"
 	"image/png"
-	"net/http"
 	"strings"
 	"time"
 )
@@ -130,35 +128,37 @@ func GetWeatherImage(ctx *bot.Context) (buf *bytes.Buffer, err error) {
 	var (
 		forecast DarkSkyResponse
 		city     = ctx.GetGuild().WeatherCity
+		user     = ctx.GetUserData()
+
+		cityName, newlat, newlng string
 	)
 
 	if len(ctx.Args) > 0 {
-		city = strings.Join(ctx.Args, "+")
+		city = strings.Join(ctx.Args, " ")
 	}
 
-	loc, err := location.New(ctx.Conf.General.GeonamesUsername, city)
-	if err != nil {
-		fmt.Printf("Location API: %v\n", err)
-		return
+	if len(ctx.Args) == 0 && user.HasLocation() {
+		// User's saved location has priority over the guild city
+		cityName = user.City
+		newlat, newlng = user.Lat, user.Lng
+	} else {
+		loc, locErr := ctx.Geocode(city)
+		if locErr != nil {
+			fmt.Printf("Location API: %v\n", locErr)
+			return nil, locErr
+		}
+		cityName = loc.Name
+		newlat, newlng = loc.Lat, loc.Lng
 	}
 
-	cityName := loc.Geonames[0].CountryName + ", " + loc.Geonames[0].Name
-
-	// Get coordinates and get weather data
-	newlat, newlng := loc.GetCoordinates()
-	resp, err := http.Get(fmt.Sprintf("https://api.darksky.net/forecast/%v/%v,%v?units=ca&lang=%v",
-		ctx.Conf.DarkSky.Token, newlat, newlng, ctx.Conf.General.Language))
+	// Get weather data
+	err = httpclient.For("darksky").GetJSON(ctx.CallContext(), fmt.Sprintf("https://api.darksky.net/forecast/%v/%v,%v?units=ca&lang=%v",
+		ctx.Conf.DarkSky.Token, newlat, newlng, ctx.Conf.General.Language), &forecast)
 	if err != nil {
 		fmt.Printf("Weather API: %v\n", err)
 		return
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(&forecast)
-	if err != nil {
-		fmt.Printf("Weather Decode: %v\n", err)
-		return
-	}
-
 	// Drawing weather widget
 	gc := gg.NewContext(400, 650)
 	gc.SetRGBA(0, 0, 0, 0)
@@ -2,11 +2,11 @@ package fun
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 )
 
 // ImageResponse contains image API response data
@@ -17,21 +17,13 @@ type ImageResponse struct {
 }
 
 // GetImageURL returns image url
-func GetImageURL(category string) (string, error) {
-	resp, err := http.Get(fmt.Sprintf("https://botimages.realpha.ru/?category=%v", category))
-	if err != nil {
-		if resp.StatusCode == http.StatusOK {
-			fmt.Printf("Getting image url error: %v", err)
-			return "", errors.New("getting image url error")
-		}
-	}
-
+func GetImageURL(ctx context.Context, category string) (string, error) {
 	var result ImageResponse
 
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	err := httpclient.For("images").GetJSON(ctx, fmt.Sprintf("https://botimages.realpha.ru/?category=%v", category), &result)
 	if err != nil {
-		fmt.Printf(err.Error())
-		return "", err
+		fmt.Printf("Getting image url error: %v", err)
+		return "", errors.New("getting image url error")
 	}
 
 	if result.Success {
@@ -41,18 +33,11 @@ func GetImageURL(category string) (string, error) {
 }
 
 // GetImage return image bytes buffer
-func GetImage(category string) (*bytes.Buffer, error) {
-	resp, err := http.Get(fmt.Sprintf("https://botimages.realpha.ru/?category=%v", category))
+func GetImage(ctx context.Context, category string) (*bytes.Buffer, error) {
+	body, err := httpclient.For("images").Get(ctx, fmt.Sprintf("https://botimages.realpha.ru/?category=%v", category))
 	if err != nil {
 		fmt.Printf("Getting image url error: %v", err)
 		return nil, errors.New("getting image url error")
 	}
-
-	buf := new(bytes.Buffer)
-
-	_, err = io.Copy(buf, resp.Body) //png.Encode(buf, resp.Body)
-	if err != nil {
-		fmt.Printf("Map image: %v", err.Error())
-	}
-	return buf, err
+	return bytes.NewBuffer(body), nil
 }
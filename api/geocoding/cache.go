@@ -0,0 +1,54 @@
+package geocoding
+
+import (
+	"sync"
+	"time"
+
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+type cachedLocation struct {
+	Location `bson:",inline"`
+	Created  time.Time
+}
+
+// MongoCache persistent cache stored in MongoDB collection with in-memory layer
+type MongoCache struct {
+	sync.RWMutex
+	collection *mgo.Collection
+	memory     map[string]*Location
+}
+
+// NewMongoCache creates cache in specified collection
+func NewMongoCache(collection *mgo.Collection) *MongoCache {
+	return &MongoCache{collection: collection, memory: make(map[string]*Location)}
+}
+
+// Get returns cached location by key
+func (c *MongoCache) Get(key string) (*Location, bool) {
+	c.RLock()
+	loc, ok := c.memory[key]
+	c.RUnlock()
+	if ok {
+		return loc, true
+	}
+
+	var cached cachedLocation
+	err := c.collection.Find(bson.M{"key": key}).One(&cached)
+	if err != nil {
+		return nil, false
+	}
+	c.Lock()
+	c.memory[key] = &cached.Location
+	c.Unlock()
+	return &cached.Location, true
+}
+
+// Set saves location in cache
+func (c *MongoCache) Set(key string, loc *Location) {
+	c.Lock()
+	c.memory[key] = loc
+	c.Unlock()
+	_, _ = c.collection.Upsert(bson.M{"key": key}, cachedLocation{Location: *loc, Created: time.Now()})
+}
@@ -0,0 +1,98 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Location contains resolved place
+type Location struct {
+	Key      string
+	Name     string
+	Lat      string
+	Lng      string
+	Provider string
+}
+
+// NotFoundError returns when no one provider found the place
+type NotFoundError struct {
+	Query string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("location \"%v\" not found", e.Query)
+}
+
+// IsNotFound returns true if error is NotFoundError
+func IsNotFound(err error) bool {
+	var nf *NotFoundError
+	return errors.As(err, &nf)
+}
+
+// Provider geocoding API
+type Provider interface {
+	// Name returns provider name
+	Name() string
+	// Geocode resolves query to location. Returns NotFoundError if place not found
+	Geocode(ctx context.Context, query string) (*Location, error)
+}
+
+// Cache stores resolved locations by normalized query
+type Cache interface {
+	Get(key string) (*Location, bool)
+	Set(key string, loc *Location)
+}
+
+// Service resolves places through providers one by one and caches results
+type Service struct {
+	Providers []Provider
+	Cache     Cache
+}
+
+// New creates geocoding service. Providers are used in specified order
+func New(cache Cache, providers ...Provider) *Service {
+	return &Service{Providers: providers, Cache: cache}
+}
+
+// NormalizeQuery returns query in form used as cache key
+func NormalizeQuery(query string) string {
+	query = strings.Replace(query, "+", " ", -1)
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// Geocode returns location of place
+func (s *Service) Geocode(ctx context.Context, query string) (*Location, error) {
+	key := NormalizeQuery(query)
+	if key == "" {
+		return nil, &NotFoundError{Query: query}
+	}
+	if s.Cache != nil {
+		if loc, ok := s.Cache.Get(key); ok {
+			return loc, nil
+		}
+	}
+
+	var lastErr error
+	for _, p := range s.Providers {
+		loc, err := p.Geocode(ctx, key)
+		if err != nil {
+			if !IsNotFound(err) {
+				lastErr = fmt.Errorf("%v: %v", p.Name(), err)
+			}
+			continue
+		}
+		loc.Key = key
+		loc.Provider = p.Name()
+		if s.Cache != nil {
+			s.Cache.Set(key, loc)
+		}
+		return loc, nil
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, &NotFoundError{Query: query}
+}
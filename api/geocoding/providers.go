@@ -0,0 +1,109 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+	"github.com/FlameInTheDark/dtbot/api/location"
+	"github.com/FlameInTheDark/dtbot/api/yageocoding"
+)
+
+// GeoNames provider based on geonames.org
+type GeoNames struct {
+	Username string
+}
+
+// Name returns provider name
+func (g *GeoNames) Name() string {
+	return "geonames"
+}
+
+// Geocode resolves query to location
+func (g *GeoNames) Geocode(ctx context.Context, query string) (*Location, error) {
+	if g.Username == "" {
+		return nil, errors.New("username not configured")
+	}
+	loc, err := location.New(ctx, g.Username, query)
+	if err != nil {
+		if err == location.ErrNotFound {
+			return nil, &NotFoundError{Query: query}
+		}
+		return nil, err
+	}
+	lat, lng := loc.GetCoordinates()
+	return &Location{Name: loc.GetName(), Lat: lat, Lng: lng}, nil
+}
+
+// Yandex provider based on Yandex geocoder
+type Yandex struct {
+	APIKey string
+}
+
+// Name returns provider name
+func (y *Yandex) Name() string {
+	return "yandex"
+}
+
+// Geocode resolves query to location
+func (y *Yandex) Geocode(ctx context.Context, query string) (*Location, error) {
+	if y.APIKey == "" {
+		return nil, errors.New("api key not configured")
+	}
+	loc, err := yageocoding.GetData(ctx, y.APIKey, query)
+	if err != nil {
+		if err == yageocoding.ErrNotFound {
+			return nil, &NotFoundError{Query: query}
+		}
+		return nil, err
+	}
+	lat, lng := loc.GetCoordinates()
+	return &Location{Name: loc.GetName(), Lat: lat, Lng: lng}, nil
+}
+
+// Nominatim provider based on OpenStreetMap Nominatim
+type Nominatim struct {
+	// UserAgent required by Nominatim usage policy
+	UserAgent string
+}
+
+type nominatimPlace struct {
+	DisplayName string `json:"display_name"`
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+}
+
+// Name returns provider name
+func (n *Nominatim) Name() string {
+	return "nominatim"
+}
+
+// Geocode resolves query to location
+func (n *Nominatim) Geocode(ctx context.Context, query string) (*Location, error) {
+	var places []nominatimPlace
+	req, err := http.NewRequest("GET", fmt.Sprintf("https://nominatim.openstreetmap.org/search?format=json&limit=1&q=%v", url.QueryEscape(query)), nil)
+	if err != nil {
+		return nil, err
+	}
+	if n.UserAgent != "" {
+		req.Header.Set("User-Agent", n.UserAgent)
+	}
+	body, err := httpclient.For("nominatim").Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(body, &places)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(places) == 0 {
+		return nil, &NotFoundError{Query: query}
+	}
+	return &Location{Name: places[0].DisplayName, Lat: places[0].Lat, Lng: places[0].Lon}, nil
+}
@@ -1,10 +1,11 @@
 package geoip
 
 import (
-	"encoding/json"
 	"fmt"
+	"net/url"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 	"github.com/FlameInTheDark/dtbot/bot"
-	"net/http"
 )
 
 // GeoIP main geoip structure. Contains geographic data about ip address
@@ -72,15 +73,11 @@ type GeoIPCountry struct {
 
 // GetGeoIP makes request to API and returns geoip data formatted to string
 func GetGeoIP(ctx *bot.Context) string {
-	resp, err := http.Get(fmt.Sprintf("http://api.sypexgeo.net/json/%v", ctx.Arg(0)))
-	if err != nil {
-		return ctx.Loc("geoip_no_data")
-	}
-
 	var result GeoIP
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	err := httpclient.For("geoip").GetJSON(ctx.CallContext(), fmt.Sprintf("http://api.sypexgeo.net/json/%v", url.PathEscape(ctx.Arg(0))), &result)
 	if err != nil {
-		return ctx.Loc("error")
+		ctx.Log("geoip", ctx.Guild.ID, err.Error())
+		return ctx.Loc("geoip_no_data")
 	}
 
 	if result.City.NameEN == "" || result.Region.NameEN == "" || result.Country.NameEN == "" {
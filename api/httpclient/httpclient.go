@@ -0,0 +1,206 @@
+// Package httpclient contains shared HTTP client of external APIs
+// with timeouts, context cancellation and status checks
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/cache"
+)
+
+const (
+	// DefaultTimeout timeout of request if provider timeout is not configured
+	DefaultTimeout = 10 * time.Second
+	// maxBodySize max size of response body
+	maxBodySize = 20 << 20
+)
+
+var (
+	mu             sync.RWMutex
+	userAgent      = "DTBot (+https://github.com/FlameInTheDark/dtbot)"
+	defaultTimeout = DefaultTimeout
+	timeouts       = make(map[string]time.Duration)
+	httpClient     = &http.Client{}
+	responseCache  *cache.Cache
+	cacheTTLs      = make(map[string]time.Duration)
+)
+
+// StatusError returns when server responds with not successful status code
+type StatusError struct {
+	URL  string
+	Code int
+	Body []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%v responded with status %v", e.URL, e.Code)
+}
+
+// IsStatus returns true if err is StatusError with specified code
+func IsStatus(err error, code int) bool {
+	if e, ok := err.(*StatusError); ok {
+		return e.Code == code
+	}
+	return false
+}
+
+// Configure sets User-Agent, default timeout and timeouts of providers by name
+func Configure(agent string, timeout time.Duration, providers map[string]time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if agent != "" {
+		userAgent = agent
+	}
+	if timeout > 0 {
+		defaultTimeout = timeout
+	}
+	for name, t := range providers {
+		timeouts[name] = t
+	}
+}
+
+// ConfigureCache sets cache of GET responses and lifetime of responses by provider name,
+// responses of providers without lifetime are not cached
+func ConfigureCache(c *cache.Cache, ttls map[string]time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	responseCache = c
+	for name, t := range ttls {
+		cacheTTLs[name] = t
+	}
+}
+
+// Cache returns cache of responses, nil if cache is not configured
+func Cache() *cache.Cache {
+	mu.RLock()
+	defer mu.RUnlock()
+	return responseCache
+}
+
+// Client makes requests to external API of provider
+type Client struct {
+	// Name of provider, used for timeout lookup and errors
+	Name string
+}
+
+// For returns client of provider
+func For(name string) *Client {
+	return &Client{Name: name}
+}
+
+// Timeout returns timeout of provider requests
+func (c *Client) Timeout() time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	if t, ok := timeouts[c.Name]; ok && t > 0 {
+		return t
+	}
+	return defaultTimeout
+}
+
+// CacheTTL returns lifetime of cached provider responses, zero if responses are not cached
+func (c *Client) CacheTTL() time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	if responseCache == nil {
+		return 0
+	}
+	return cacheTTLs[c.Name]
+}
+
+// Do sends request and returns response body,
+// body is always closed and non 2xx status returns StatusError
+func (c *Client) Do(ctx context.Context, req *http.Request) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout())
+	defer cancel()
+
+	mu.RLock()
+	agent := userAgent
+	mu.RUnlock()
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", agent)
+	}
+
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%v request error: %v", c.Name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("%v read error: %v", c.Name, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return body, &StatusError{URL: req.URL.Host + req.URL.Path, Code: resp.StatusCode, Body: body}
+	}
+	return body, nil
+}
+
+// Get sends GET request and returns response body,
+// successful responses are cached if provider has cache lifetime
+func (c *Client) Get(ctx context.Context, url string) ([]byte, error) {
+	ttl := c.CacheTTL()
+	// URL is hashed because it may contain API keys
+	sum := sha1.Sum([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	if ttl > 0 {
+		if body, ok := Cache().Get(c.Name, key); ok {
+			return body, nil
+		}
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Do(ctx, req)
+	if err == nil && ttl > 0 {
+		Cache().Set(c.Name, key, body, ttl)
+	}
+	return body, err
+}
+
+// GetJSON sends GET request and decodes JSON response in result
+func (c *Client) GetJSON(ctx context.Context, url string, result interface{}) error {
+	body, err := c.Get(ctx, url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, result)
+}
+
+// GetXML sends GET request and decodes XML response in result
+func (c *Client) GetXML(ctx context.Context, url string, result interface{}) error {
+	body, err := c.Get(ctx, url)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(body, result)
+}
+
+// Post sends POST request with body and returns response body
+func (c *Client) Post(ctx context.Context, url, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.Do(ctx, req)
+}
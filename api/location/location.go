@@ -1,12 +1,17 @@
 package location
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
+	"net/url"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 )
 
+// ErrNotFound returns if location not found
+var ErrNotFound = errors.New("City not found")
+
 // LocationResultData : location struct
 type LocationResultData struct {
 	ResultCount int       `json:"totalResultsCount"`
@@ -31,20 +36,21 @@ func (l LocationResultData) GetCoordinates() (string, string) {
 	return l.Geonames[0].Lat, l.Geonames[0].Lng
 }
 
-// New creates and returns location struct
-func New(user string, locationName string) (result LocationResultData, err error) {
-	resp, err := http.Get(fmt.Sprintf("http://api.geonames.org/searchJSON?q=%v&maxRows=1&username=%v", locationName, user))
-	if err != nil {
-		return result, err
-	}
+// GetName returns country and name of first location
+func (l LocationResultData) GetName() string {
+	return l.Geonames[0].CountryName + ", " + l.Geonames[0].Name
+}
 
-	err = json.NewDecoder(resp.Body).Decode(&result)
+// New creates and returns location struct
+func New(ctx context.Context, user string, locationName string) (result LocationResultData, err error) {
+	err = httpclient.For("geonames").GetJSON(ctx, fmt.Sprintf("http://api.geonames.org/searchJSON?q=%v&maxRows=1&username=%v",
+		url.QueryEscape(locationName), url.QueryEscape(user)), &result)
 	if err != nil {
 		return result, err
 	}
 
 	if len(result.Geonames) == 0 {
-		return result, errors.New("City not found")
+		return result, ErrNotFound
 	}
 
 	return result, nil
@@ -0,0 +1,239 @@
+package news
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+// Feed contains parsed RSS or Atom feed
+type Feed struct {
+	Title string
+	Link  string
+	Items []FeedItem
+}
+
+// FeedItem contains feed entry
+type FeedItem struct {
+	GUID        string
+	Title       string
+	Link        string
+	Description string
+	ImageURL    string
+	Published   time.Time
+}
+
+type xmlFeed struct {
+	XMLName xml.Name
+	// RSS 2.0
+	Channel struct {
+		Title string    `xml:"title"`
+		Links []string  `xml:"link"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 contains items in root element
+	Items []rssItem `xml:"item"`
+	// Atom
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	GUID        string   `xml:"guid"`
+	Title       string   `xml:"title"`
+	Links       []string `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Enclosure   struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+	Thumbnail struct {
+		URL string `xml:"url,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Group     struct {
+		Thumbnail struct {
+			URL string `xml:"url,attr"`
+		} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+		Description string `xml:"http://search.yahoo.com/mrss/ description"`
+	} `xml:"http://search.yahoo.com/mrss/ group"`
+}
+
+var (
+	feedTimeFormats = []string{
+		time.RFC1123Z,
+		time.RFC1123,
+		time.RFC3339,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+		"2 Jan 2006 15:04:05 -0700",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	}
+	htmlTags = regexp.MustCompile(`<[^>]*>`)
+)
+
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, f := range feedTimeFormats {
+		if t, err := time.Parse(f, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// cleanText removes html tags and entities
+func cleanText(text string) string {
+	text = htmlTags.ReplaceAllString(text, "")
+	return strings.TrimSpace(html.UnescapeString(text))
+}
+
+// rssLink returns first non empty link. Feeds may contain empty atom:link elements with same name
+func rssLink(links []string) string {
+	for _, l := range links {
+		if strings.TrimSpace(l) != "" {
+			return strings.TrimSpace(l)
+		}
+	}
+	return ""
+}
+
+func atomHref(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+func atomImage(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "enclosure" && strings.HasPrefix(l.Type, "image/") {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+// ParseFeed parses RSS 1.0, RSS 2.0 or Atom feed
+func ParseFeed(r io.Reader) (*Feed, error) {
+	var data xmlFeed
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "utf-8", "us-ascii", "ascii":
+			return input, nil
+		}
+		return nil, fmt.Errorf("unsupported charset %v", charset)
+	}
+	err := decoder.Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed Feed
+	switch data.XMLName.Local {
+	case "rss", "RDF":
+		feed.Title = data.Channel.Title
+		feed.Link = rssLink(data.Channel.Links)
+		items := append(data.Channel.Items, data.Items...)
+		for _, i := range items {
+			item := FeedItem{
+				GUID:        strings.TrimSpace(i.GUID),
+				Title:       cleanText(i.Title),
+				Link:        rssLink(i.Links),
+				Description: cleanText(i.Description),
+				Published:   parseFeedTime(i.PubDate),
+			}
+			if item.Published.IsZero() {
+				item.Published = parseFeedTime(i.Date)
+			}
+			if strings.HasPrefix(i.Enclosure.Type, "image/") {
+				item.ImageURL = i.Enclosure.URL
+			} else if i.Thumbnail.URL != "" {
+				item.ImageURL = i.Thumbnail.URL
+			}
+			feed.Items = append(feed.Items, item)
+		}
+	case "feed":
+		feed.Title = data.Title
+		feed.Link = atomHref(data.Links)
+		for _, e := range data.Entries {
+			item := FeedItem{
+				GUID:        strings.TrimSpace(e.ID),
+				Title:       cleanText(e.Title),
+				Link:        atomHref(e.Links),
+				Description: cleanText(e.Summary),
+				ImageURL:    atomImage(e.Links),
+				Published:   parseFeedTime(e.Published),
+			}
+			if item.Description == "" {
+				item.Description = cleanText(e.Content)
+			}
+			if item.Description == "" {
+				item.Description = cleanText(e.Group.Description)
+			}
+			if item.ImageURL == "" {
+				item.ImageURL = e.Group.Thumbnail.URL
+			}
+			if item.Published.IsZero() {
+				item.Published = parseFeedTime(e.Updated)
+			}
+			feed.Items = append(feed.Items, item)
+		}
+	default:
+		return nil, errors.New("unknown feed format")
+	}
+
+	for i := range feed.Items {
+		// Not all feeds have guid, so link or title are used instead
+		if feed.Items[i].GUID == "" {
+			feed.Items[i].GUID = feed.Items[i].Link
+		}
+		if feed.Items[i].GUID == "" {
+			feed.Items[i].GUID = feed.Items[i].Title
+		}
+	}
+	return &feed, nil
+}
+
+// GetFeed downloads and parses feed
+func GetFeed(ctx context.Context, url string) (*Feed, error) {
+	body, err := httpclient.For("feeds").Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFeed(bytes.NewReader(body))
+}
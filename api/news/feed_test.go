@@ -0,0 +1,136 @@
+package news
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseFixture(t *testing.T, name string) *Feed {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	feed, err := ParseFeed(file)
+	if err != nil {
+		t.Fatalf("parsing %v: %v", name, err)
+	}
+	return feed
+}
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		file  string
+		title string
+		link  string
+		items []FeedItem
+	}{
+		{
+			file:  "rss2.xml",
+			title: "Example News",
+			link:  "https://news.example.com/",
+			items: []FeedItem{
+				{
+					GUID:        "news-1",
+					Title:       "First & foremost",
+					Link:        "https://news.example.com/1",
+					Description: "Story with tags",
+					ImageURL:    "https://news.example.com/1.jpg",
+					Published:   time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+				},
+				{
+					GUID:        "https://news.example.com/2",
+					Title:       "Second story",
+					Link:        "https://news.example.com/2",
+					Description: "No guid here",
+					ImageURL:    "https://news.example.com/2.jpg",
+					Published:   time.Date(2006, 1, 3, 10, 0, 0, 0, time.UTC),
+				},
+				{
+					GUID:        "Only title",
+					Title:       "Only title",
+					Description: "Neither guid nor link",
+				},
+			},
+		},
+		{
+			file:  "rss1.xml",
+			title: "RDF Site",
+			link:  "https://rdf.example.com/",
+			items: []FeedItem{
+				{
+					GUID:        "https://rdf.example.com/a",
+					Title:       "RDF item",
+					Link:        "https://rdf.example.com/a",
+					Description: "RDF description",
+					Published:   time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+				},
+			},
+		},
+		{
+			file:  "atom.xml",
+			title: "Atom Blog",
+			link:  "https://blog.example.com/",
+			items: []FeedItem{
+				{
+					GUID:        "tag:blog.example.com,2006:1",
+					Title:       "Atom entry",
+					Link:        "https://blog.example.com/1",
+					Description: "Content only",
+					ImageURL:    "https://blog.example.com/1.png",
+					Published:   time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+				},
+			},
+		},
+		{
+			file:  "youtube.xml",
+			title: "Example Channel",
+			link:  "https://www.youtube.com/channel/UC123",
+			items: []FeedItem{
+				{
+					GUID:        "yt:video:abc123",
+					Title:       "Video title",
+					Link:        "https://www.youtube.com/watch?v=abc123",
+					Description: "Video description",
+					ImageURL:    "https://i.ytimg.com/vi/abc123/hqdefault.jpg",
+					Published:   time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			feed := parseFixture(t, tt.file)
+			if feed.Title != tt.title {
+				t.Errorf("title = %q, want %q", feed.Title, tt.title)
+			}
+			if feed.Link != tt.link {
+				t.Errorf("link = %q, want %q", feed.Link, tt.link)
+			}
+			if len(feed.Items) != len(tt.items) {
+				t.Fatalf("got %v items, want %v", len(feed.Items), len(tt.items))
+			}
+			for i, want := range tt.items {
+				got := feed.Items[i]
+				if !got.Published.Equal(want.Published) {
+					t.Errorf("item %v published = %v, want %v", i, got.Published, want.Published)
+				}
+				got.Published, want.Published = time.Time{}, time.Time{}
+				if got != want {
+					t.Errorf("item %v = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFeedUnknownFormat(t *testing.T) {
+	if _, err := ParseFeed(strings.NewReader("<html><body></body></html>")); err == nil {
+		t.Error("expected error for unknown feed format")
+	}
+}
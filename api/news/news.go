@@ -1,16 +1,26 @@
 package news
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 	"github.com/FlameInTheDark/dtbot/bot"
-	"net/http"
+)
+
+const (
+	newsAPIURL = "https://newsapi.org/v2/"
+	// pageSize count of items on one page of results
+	pageSize = 5
 )
 
 // NewsResponseData : News main struct
 type NewsResponseData struct {
 	Status       string            `json:"status"`
+	Code         string            `json:"code"`
+	Message      string            `json:"message"`
 	TotalResults int               `json:"totalResults"`
 	Articles     []NewsArticleData `json:"articles"`
 }
@@ -31,39 +41,149 @@ type NewsArticeleSourceData struct {
 	Name string `json:"name"`
 }
 
-// GetNews returns news string
-func GetNews(ctx *bot.Context) error {
-	var (
-		result   NewsResponseData
-		category string
-	)
-	if len(ctx.Args) > 0 {
-		category = ctx.Args[0]
-	}
-	resp, err := http.Get(fmt.Sprintf("https://newsapi.org/v2/top-headlines?country=%v&category=%v&apiKey=%v", ctx.Conf.News.Country, category, ctx.Conf.News.APIKey))
+// NewsSourcesData : News sources list struct
+type NewsSourcesData struct {
+	Status  string           `json:"status"`
+	Message string           `json:"message"`
+	Sources []NewsSourceData `json:"sources"`
+}
+
+// NewsSourceData : News source struct
+type NewsSourceData struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	Category    string `json:"category"`
+	Language    string `json:"language"`
+	Country     string `json:"country"`
+}
+
+func request(ctx *bot.Context, method string, params url.Values, result interface{}) error {
+	params.Set("apiKey", ctx.Conf.News.APIKey)
+	err := httpclient.For("newsapi").GetJSON(ctx.CallContext(), newsAPIURL+method+"?"+params.Encode(), result)
 	if err != nil {
 		ctx.Log("news", ctx.Guild.ID, fmt.Sprintf("Get news error: %v", err))
-		return fmt.Errorf("get news error: %v", err)
+		return errors.New(ctx.Loc("news_api_error"))
+	}
+	return nil
+}
+
+// filterArticles removes articles of sources denied in guild
+func filterArticles(guild *bot.GuildData, articles []NewsArticleData) []NewsArticleData {
+	var result []NewsArticleData
+	for _, a := range articles {
+		if (a.Source.Id == "" && len(guild.NewsSources) == 0) || guild.NewsSourceAllowed(a.Source.Id) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func getArticles(ctx *bot.Context, method string, params url.Values) ([]NewsArticleData, error) {
+	var result NewsResponseData
+	guild := ctx.GetGuild()
+	if len(guild.NewsSources) > 0 {
+		params.Set("sources", strings.Join(guild.NewsSources, ","))
+	}
+	if err := request(ctx, method, params, &result); err != nil {
+		return nil, err
+	}
+	if result.Status != "ok" {
+		ctx.Log("news", ctx.Guild.ID, fmt.Sprintf("News API error: %v %v", result.Code, result.Message))
+		return nil, errors.New(ctx.Loc("news_api_error"))
+	}
+	articles := filterArticles(guild, result.Articles)
+	if len(articles) == 0 {
+		return nil, errors.New(ctx.Loc("news_404"))
 	}
+	return articles, nil
+}
+
+// GetNews sends top headlines of guild country in specified category
+func GetNews(ctx *bot.Context) error {
+	params := url.Values{}
+	if len(ctx.GetGuild().NewsSources) == 0 {
+		// NewsAPI does not allow to mix country and category with sources
+		params.Set("country", ctx.GetGuild().NewsCounty)
+		if len(ctx.Args) > 0 {
+			params.Set("category", ctx.Args[0])
+		}
+	}
+	articles, err := getArticles(ctx, "top-headlines", params)
+	if err != nil {
+		return err
+	}
+	if len(articles) > ctx.Conf.News.Articles {
+		articles = articles[:ctx.Conf.News.Articles]
+	}
+	emb := bot.NewEmbed(ctx.Loc("news"))
+	for _, a := range articles {
+		emb.Field(a.Title, a.Description+"\n"+a.URL, false)
+	}
+	emb.Footer(fmt.Sprintf("%v %v", ctx.Loc("requested_by"), ctx.Message.Author.Username))
+	emb.Color(ctx.GetGuild().EmbedColor)
+	emb.Send(ctx)
+	return nil
+}
 
-	err = json.NewDecoder(resp.Body).Decode(&result)
+// Search sends pages of news found by query
+func Search(ctx *bot.Context, query string) error {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("sortBy", "publishedAt")
+	if lang := ctx.GetGuild().NewsLanguage; lang != "" {
+		params.Set("language", lang)
+	}
+	articles, err := getArticles(ctx, "everything", params)
 	if err != nil {
-		ctx.Log("news", ctx.Guild.ID, fmt.Sprintf("Parse news error: %v", err))
-		return fmt.Errorf("parse news error: %v", err)
+		return err
 	}
+	var embeds []*bot.NewEmbedStruct
+	for i := 0; i < len(articles); i += pageSize {
+		emb := bot.NewEmbed(fmt.Sprintf("%v: %v", ctx.Loc("news_search"), query))
+		for j := i; j < i+pageSize && j < len(articles); j++ {
+			a := articles[j]
+			emb.Field(a.Title, fmt.Sprintf("%v\n_%v_ %v", a.Description, a.Source.Name, a.URL), false)
+		}
+		embeds = append(embeds, emb)
+	}
+	ctx.SendPages(embeds)
+	return nil
+}
 
-	if result.Status == "ok" {
-		if len(result.Articles) > 0 {
-			emb := bot.NewEmbed(ctx.Loc("news"))
-			for i := 0; i < ctx.Conf.News.Articles; i++ {
-				emb.Field(result.Articles[i].Title, result.Articles[i].Description+"\n"+result.Articles[i].URL, false)
+// GetSources sends pages of news sources available for guild country and language
+func GetSources(ctx *bot.Context) error {
+	var result NewsSourcesData
+	guild := ctx.GetGuild()
+	params := url.Values{}
+	params.Set("country", guild.NewsCounty)
+	if guild.NewsLanguage != "" {
+		params.Set("language", guild.NewsLanguage)
+	}
+	if err := request(ctx, "sources", params, &result); err != nil {
+		return err
+	}
+	if result.Status != "ok" {
+		ctx.Log("news", ctx.Guild.ID, fmt.Sprintf("News API error: %v", result.Message))
+		return errors.New(ctx.Loc("news_api_error"))
+	}
+	if len(result.Sources) == 0 {
+		return errors.New(ctx.Loc("news_sources_404"))
+	}
+	var embeds []*bot.NewEmbedStruct
+	for i := 0; i < len(result.Sources); i += pageSize * 2 {
+		emb := bot.NewEmbed(ctx.Loc("news_sources"))
+		for j := i; j < i+pageSize*2 && j < len(result.Sources); j++ {
+			s := result.Sources[j]
+			name := s.Name
+			if !guild.NewsSourceAllowed(s.Id) {
+				name = "~~" + name + "~~"
 			}
-			emb.Footer(fmt.Sprintf("%v %v", ctx.Loc("requested_by"), ctx.Message.Author.Username))
-			emb.Color(ctx.Conf.General.EmbedColor)
-			_, _ = ctx.Discord.ChannelMessageSendEmbed(ctx.Message.ChannelID, emb.GetEmbed())
-			return nil
+			emb.Field(name, fmt.Sprintf("`%v` | %v\n%v", s.Id, s.Category, s.URL), false)
 		}
-		return errors.New(ctx.Loc("news_404"))
+		embeds = append(embeds, emb)
 	}
-	return errors.New(ctx.Loc("news_api_error"))
+	ctx.SendPages(embeds)
+	return nil
 }
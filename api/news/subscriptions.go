@@ -0,0 +1,180 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/bot"
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxFeedPosts maximum count of items posted from one feed per update
+const maxFeedPosts = 5
+
+// Subscribe subscribes current channel to feed. Current feed items are marked as seen
+func Subscribe(ctx *bot.Context, feedURL string) (*bot.NewsFeed, error) {
+	if u, err := url.Parse(feedURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.New(ctx.Loc("news_feed_wrong_url"))
+	}
+	limit := ctx.Conf.News.FeedsLimit
+	if limit == 0 {
+		limit = 10
+	}
+	feeds := ctx.DB.GetNewsFeeds(ctx.Guild.ID)
+	if len(feeds) >= limit {
+		return nil, fmt.Errorf(ctx.Loc("news_feeds_limit"), limit)
+	}
+	for _, f := range feeds {
+		if f.Channel == ctx.TextChannel.ID && f.URL == feedURL {
+			return nil, errors.New(ctx.Loc("news_feed_exists"))
+		}
+	}
+
+	feed, err := GetFeed(ctx.CallContext(), feedURL)
+	if err != nil {
+		ctx.Log("news", ctx.Guild.ID, fmt.Sprintf("Get feed error: %v", err))
+		return nil, errors.New(ctx.Loc("news_feed_error"))
+	}
+	for _, item := range feed.Items {
+		if !ctx.DB.IsFeedItemSeen(feedURL, item.GUID) {
+			ctx.DB.AddFeedItemSeen(feedURL, item.GUID)
+		}
+	}
+
+	sub := &bot.NewsFeed{Guild: ctx.Guild.ID, Channel: ctx.TextChannel.ID, URL: feedURL, Title: feed.Title}
+	if sub.Title == "" {
+		sub.Title = feedURL
+	}
+	if err := ctx.DB.AddNewsFeed(sub); err != nil {
+		return nil, errors.New(ctx.Loc("error"))
+	}
+	return sub, nil
+}
+
+// FeedEmbed returns embed of feed item
+func FeedEmbed(feedTitle string, item *FeedItem, color int) *bot.NewEmbedStruct {
+	emb := bot.NewEmbed(bot.TruncateText(item.Title, 256)).
+		URL(item.Link).
+		Author(feedTitle, "", "").
+		Desc(bot.TruncateText(item.Description, 500)).
+		Color(color)
+	if item.ImageURL != "" {
+		emb.AttachImgURL(item.ImageURL)
+	}
+	if !item.Published.IsZero() {
+		emb.TimeStamp(item.Published.Format(time.RFC3339))
+	}
+	return emb
+}
+
+// feedItem contains feed item with title of feed
+type feedItem struct {
+	FeedTitle string
+	Item      *FeedItem
+}
+
+// feedSource polls subscribed feeds
+type feedSource struct {
+	db *bot.DBWorker
+}
+
+// Poll returns items of feeds from oldest to newest, feeds with errors are skipped
+func (src *feedSource) Poll(ctx context.Context, subjects []string) (map[string][]bot.NotifyItem, error) {
+	var items = make(map[string][]bot.NotifyItem)
+	for _, feedURL := range subjects {
+		feed, err := GetFeed(ctx, feedURL)
+		if err != nil {
+			src.db.Log("news", "", fmt.Sprintf("Get feed [%v] error: %v", feedURL, err))
+			continue
+		}
+		var feedItems []bot.NotifyItem
+		// Feeds usually list items from newest to oldest
+		for i := len(feed.Items) - 1; i >= 0; i-- {
+			feedItems = append(feedItems, bot.NotifyItem{
+				ID:   feed.Items[i].GUID,
+				Data: &feedItem{FeedTitle: feed.Title, Item: &feed.Items[i]},
+			})
+		}
+		items[feedURL] = feedItems
+	}
+	return items, nil
+}
+
+// feedDetector finds items not posted yet, seen items are shared by all subscriptions of feed
+type feedDetector struct {
+	db *bot.DBWorker
+}
+
+// Detect returns new items of feed for every subscribed channel
+func (d *feedDetector) Detect(subject string, subs []bot.NotifySubscription, items []bot.NotifyItem) []bot.NotifyEvent {
+	var events []bot.NotifyEvent
+	var posted int
+	for _, item := range items {
+		if d.db.IsFeedItemSeen(subject, item.ID) {
+			continue
+		}
+		d.db.AddFeedItemSeen(subject, item.ID)
+		if posted >= maxFeedPosts {
+			continue
+		}
+		posted++
+		for _, s := range subs {
+			events = append(events, bot.NotifyEvent{Type: bot.NotifyStarted, Subscription: s, Item: item})
+		}
+	}
+	return events
+}
+
+// feedRenderer makes embeds of feed items in guild color
+type feedRenderer struct {
+	guilds *bot.GuildsMap
+	conf   *bot.Config
+}
+
+// Render makes embed of feed item
+func (r *feedRenderer) Render(ctx context.Context, event bot.NotifyEvent) *bot.NewEmbedStruct {
+	item := event.Item.Data.(*feedItem)
+	color := r.conf.General.EmbedColor
+	if g, ok := r.guilds.Get(event.Subscription.(bot.NewsFeed).Guild); ok {
+		color = g.EmbedColor
+	}
+	return FeedEmbed(item.FeedTitle, item.Item, color)
+}
+
+// feedStore loads feed subscriptions from database
+type feedStore struct {
+	db *bot.DBWorker
+}
+
+// Subscriptions returns feed subscriptions of all guilds
+func (st *feedStore) Subscriptions() []bot.NotifySubscription {
+	var subs []bot.NotifySubscription
+	for _, f := range st.db.GetNewsFeeds("") {
+		subs = append(subs, f)
+	}
+	return subs
+}
+
+// Save does nothing, seen items are saved by detector
+func (st *feedStore) Save(event bot.NotifyEvent, messageID string) {}
+
+// FeedNotifier makes notifier of subscribed feeds
+func FeedNotifier(session *discordgo.Session, db *bot.DBWorker, guilds *bot.GuildsMap, conf *bot.Config) *bot.Notifier {
+	interval := time.Duration(conf.News.FeedInterval) * time.Minute
+	if interval == 0 {
+		interval = time.Minute * 10
+	}
+	return &bot.Notifier{
+		Name:     "news",
+		Interval: interval,
+		Source:   &feedSource{db},
+		Detector: &feedDetector{db},
+		Renderer: &feedRenderer{guilds, conf},
+		Store:    &feedStore{db},
+		Discord:  session,
+		DB:       db,
+	}
+}
@@ -0,0 +1,36 @@
+package translate
+
+import "strings"
+
+// FlagLanguages maps ISO 3166-1 country codes to languages
+var FlagLanguages = map[string]string{
+	"US": "en", "GB": "en", "AU": "en", "CA": "en", "NZ": "en", "IE": "en",
+	"RU": "ru", "BY": "be", "UA": "uk", "KZ": "kk", "UZ": "uz", "KG": "ky",
+	"DE": "de", "AT": "de", "FR": "fr", "BE": "nl", "NL": "nl", "IT": "it",
+	"ES": "es", "MX": "es", "AR": "es", "CO": "es", "CL": "es", "PE": "es",
+	"PT": "pt", "BR": "pt", "PL": "pl", "CZ": "cs", "SK": "sk", "HU": "hu",
+	"RO": "ro", "BG": "bg", "RS": "sr", "HR": "hr", "SI": "sl", "GR": "el",
+	"TR": "tr", "SE": "sv", "NO": "no", "DK": "da", "FI": "fi", "EE": "et",
+	"LV": "lv", "LT": "lt", "IS": "is", "GE": "ka", "AM": "hy", "AZ": "az",
+	"IL": "he", "SA": "ar", "EG": "ar", "AE": "ar", "IR": "fa", "IN": "hi",
+	"PK": "ur", "BD": "bn", "CN": "zh", "TW": "zh", "JP": "ja", "KR": "ko",
+	"VN": "vi", "TH": "th", "ID": "id", "MY": "ms", "PH": "tl", "MN": "mn",
+}
+
+// FlagLanguage returns language of country flag emoji
+func FlagLanguage(emoji string) (string, bool) {
+	runes := []rune(emoji)
+	if len(runes) != 2 {
+		return "", false
+	}
+	var code strings.Builder
+	for _, r := range runes {
+		// Flags are pairs of regional indicator symbols
+		if r < 0x1F1E6 || r > 0x1F1FF {
+			return "", false
+		}
+		code.WriteRune('A' + r - 0x1F1E6)
+	}
+	lang, ok := FlagLanguages[code.String()]
+	return lang, ok
+}
@@ -0,0 +1,32 @@
+package translate
+
+import "testing"
+
+func TestFlagLanguage(t *testing.T) {
+	tests := []struct {
+		name  string
+		emoji string
+		lang  string
+		ok    bool
+	}{
+		{"russia", "🇷🇺", "ru", true},
+		{"united kingdom", "🇬🇧", "en", true},
+		{"brazil", "🇧🇷", "pt", true},
+		{"japan", "🇯🇵", "ja", true},
+		{"country without language", "🇦🇶", "", false},
+		{"single indicator", "🇷", "", false},
+		{"three indicators", "🇷🇺🇸", "", false},
+		{"letters", "RU", "", false},
+		{"other emoji", "👍", "", false},
+		{"emoji with modifier", "👍🏻", "", false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang, ok := FlagLanguage(tt.emoji)
+			if lang != tt.lang || ok != tt.ok {
+				t.Errorf("FlagLanguage(%q) = %q, %v, want %q, %v", tt.emoji, lang, ok, tt.lang, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+// LibreTranslate self-hosted LibreTranslate backend
+type LibreTranslate struct {
+	URL    string
+	APIKey string
+}
+
+type libreTranslateResponse struct {
+	TranslatedText   string `json:"translatedText"`
+	DetectedLanguage struct {
+		Language string `json:"language"`
+	} `json:"detectedLanguage"`
+	Error string `json:"error"`
+}
+
+type libreDetectResponse []struct {
+	Language   string  `json:"language"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Name returns backend name
+func (l *LibreTranslate) Name() string {
+	return "libretranslate"
+}
+
+func (l *LibreTranslate) post(ctx context.Context, method string, request map[string]string, result interface{}) error {
+	if l.URL == "" {
+		return ErrNotConfigured
+	}
+	if l.APIKey != "" {
+		request["api_key"] = l.APIKey
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	body, err = httpclient.For("libretranslate").Post(ctx, strings.TrimRight(l.URL, "/")+"/"+method, "application/json", body)
+	if err != nil {
+		if _, ok := err.(*httpclient.StatusError); ok {
+			var apiErr libreTranslateResponse
+			if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+				return errors.New(apiErr.Error)
+			}
+		}
+		return err
+	}
+	return json.Unmarshal(body, result)
+}
+
+// Translate translates text to target language
+func (l *LibreTranslate) Translate(ctx context.Context, text, source, target string) (*Result, error) {
+	var result libreTranslateResponse
+	if source == "" {
+		source = "auto"
+	}
+	err := l.post(ctx, "translate", map[string]string{"q": text, "source": source, "target": target, "format": "text"}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if source == "auto" {
+		source = result.DetectedLanguage.Language
+	}
+	return &Result{Text: result.TranslatedText, Source: source}, nil
+}
+
+// Detect returns language of text
+func (l *LibreTranslate) Detect(ctx context.Context, text string) (string, error) {
+	var result libreDetectResponse
+	err := l.post(ctx, "detect", map[string]string{"q": text}, &result)
+	if err != nil {
+		return "", err
+	}
+	if len(result) == 0 {
+		return "", errors.New("language not detected")
+	}
+	return result[0].Language, nil
+}
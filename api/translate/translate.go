@@ -1,53 +1,151 @@
 package translate
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/FlameInTheDark/dtbot/bot"
+	"github.com/bwmarrin/discordgo"
 )
 
-// TranslateResponse : Translate API struct
-type TranslateResponse struct {
-	Code     int      `json:"code"`
-	Language string   `json:"lang"`
-	Text     []string `json:"text"`
-	Message  string   `json:"message"`
-}
-
-// GetTranslation returns translated text
+// GetTranslation returns translated text. Arguments: [target_lang|source-target] text
 func GetTranslation(ctx *bot.Context) (string, error) {
 	var (
-		result    TranslateResponse
-		translate string
+		text           string
+		source, target string
 	)
 
-	if len(ctx.Args) > 1 {
-		translate = strings.Join(ctx.Args[1:], "+")
-	} else {
+	if len(ctx.Args) == 0 {
 		return "", errors.New(ctx.Loc("translate_request_error"))
 	}
 
-	resp, err := http.Get(fmt.Sprintf("https://translate.yandex.net/api/v1.5/tr.json/translate?key=%v&text=%v&lang=%v&format=plain", ctx.Conf.Translate.APIKey, translate, ctx.Args[0]))
+	if s, t, ok := ParseLanguages(ctx.Args[0]); ok {
+		if len(ctx.Args) < 2 {
+			return "", errors.New(ctx.Loc("translate_request_error"))
+		}
+		source, target = s, t
+		text = strings.Join(ctx.Args[1:], " ")
+	} else {
+		// Without language argument text translates to guild language
+		target = ctx.GetGuild().Language
+		text = strings.Join(ctx.Args, " ")
+	}
+
+	result, err := Translate(ctx.CallContext(), ctx.Conf, text, source, target)
 	if err != nil {
+		if err == ErrNotConfigured {
+			return "", errors.New(ctx.Loc("translate_api_error"))
+		}
 		return "", fmt.Errorf("%v: %v", ctx.Loc("translate_get_error"), err)
 	}
+	return fmt.Sprintf("`%v → %v`\n%v", result.Source, target, result.Text), nil
+}
 
-	err = json.NewDecoder(resp.Body).Decode(&result)
+// AutoTranslate mirrors message to channels configured for automatic translation
+func AutoTranslate(session *discordgo.Session, message *discordgo.MessageCreate, guild *bot.GuildData, conf *bot.Config) {
+	if guild == nil || message.Author == nil || message.Author.Bot || strings.TrimSpace(message.Content) == "" {
+		return
+	}
+	rules := guild.GetAutoTranslate(message.ChannelID)
+	if len(rules) == 0 {
+		return
+	}
+	// Source language is detected once for all target channels
+	source, err := Detect(context.Background(), conf, message.Content)
 	if err != nil {
-		return "", fmt.Errorf("%v: %v", ctx.Loc("translate_parse_error"), err)
+		fmt.Println("Auto translate detect error: ", err.Error())
+		source = ""
 	}
+	for _, rule := range rules {
+		// Message already in target language
+		if source == rule.Language {
+			continue
+		}
+		result, err := Translate(context.Background(), conf, message.Content, source, rule.Language)
+		if err != nil {
+			fmt.Println("Auto translate error: ", err.Error())
+			continue
+		}
+		emb := bot.NewEmbed("").
+			Author(message.Author.Username, "", message.Author.AvatarURL("")).
+			Desc(result.Text).
+			URL(fmt.Sprintf("https://discordapp.com/channels/%v/%v/%v", message.GuildID, message.ChannelID, message.ID)).
+			Footer(fmt.Sprintf("%v → %v", result.Source, rule.Language)).
+			Color(guild.EmbedColor)
+		_, err = session.ChannelMessageSendEmbed(rule.Target, emb.GetEmbed())
+		if err != nil {
+			fmt.Println("Error whilst sending auto translation, ", err.Error())
+		}
+	}
+}
 
-	// Checking request status
-	switch result.Code {
-	case 502:
-		return "", errors.New(ctx.Loc("translate_request_error"))
-	case 200:
-		return strings.Join(result.Text, "\n"), nil
-	default:
-		return "", errors.New(ctx.Loc("translate_api_error"))
+var (
+	reactionsMutex sync.Mutex
+	// reactionsDone contains translated messages to avoid duplicates when several users react with same flag
+	reactionsDone = make(map[string]bool)
+)
+
+// ReactionTranslate replies with translation of message when user reacts with country flag
+func ReactionTranslate(session *discordgo.Session, reaction *discordgo.MessageReactionAdd, guild *bot.GuildData, conf *bot.Config) {
+	if guild == nil || !guild.ReactionTranslate {
+		return
+	}
+	lang, ok := FlagLanguage(reaction.Emoji.Name)
+	if !ok {
+		return
+	}
+
+	key := reaction.MessageID + lang
+	reactionsMutex.Lock()
+	if reactionsDone[key] {
+		reactionsMutex.Unlock()
+		return
+	}
+	if len(reactionsDone) > 1000 {
+		reactionsDone = make(map[string]bool)
+	}
+	reactionsDone[key] = true
+	reactionsMutex.Unlock()
+	// message is marked while it is translated, mark is removed after errors, so next reaction retries
+	var failed = true
+	defer func() {
+		if failed {
+			reactionsMutex.Lock()
+			delete(reactionsDone, key)
+			reactionsMutex.Unlock()
+		}
+	}()
+
+	message, err := session.ChannelMessage(reaction.ChannelID, reaction.MessageID)
+	if err != nil {
+		return
+	}
+	if message.Author == nil || strings.TrimSpace(message.Content) == "" {
+		failed = false
+		return
+	}
+	result, err := Translate(context.Background(), conf, message.Content, "", lang)
+	if err != nil {
+		fmt.Println("Reaction translate error: ", err.Error())
+		return
+	}
+	if result.Source == lang {
+		failed = false
+		return
+	}
+	emb := bot.NewEmbed("").
+		Author(message.Author.Username, "", message.Author.AvatarURL("")).
+		Desc(result.Text).
+		URL(fmt.Sprintf("https://discordapp.com/channels/%v/%v/%v", reaction.GuildID, reaction.ChannelID, reaction.MessageID)).
+		Footer(fmt.Sprintf("%v %v → %v", reaction.Emoji.Name, result.Source, lang)).
+		Color(guild.EmbedColor)
+	_, err = session.ChannelMessageSendEmbed(reaction.ChannelID, emb.GetEmbed())
+	if err != nil {
+		fmt.Println("Error whilst sending reaction translation, ", err.Error())
+		return
 	}
+	failed = false
 }
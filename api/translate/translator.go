@@ -0,0 +1,94 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/FlameInTheDark/dtbot/bot"
+)
+
+// ErrNotConfigured returns if translation backend is not configured
+var ErrNotConfigured = errors.New("translator is not configured")
+
+// Result contains translated text
+type Result struct {
+	Text string
+	// Source is language of original text, detected if it was not specified
+	Source string
+}
+
+// Translator translation API backend
+type Translator interface {
+	// Name returns backend name
+	Name() string
+	// Translate translates text to target language. Source language detects automatically if empty
+	Translate(ctx context.Context, text, source, target string) (*Result, error)
+	// Detect returns language of text
+	Detect(ctx context.Context, text string) (string, error)
+}
+
+// Languages ISO 639-1 codes used to recognize language argument
+var Languages = map[string]bool{
+	"af": true, "ar": true, "az": true, "be": true, "bg": true, "bn": true, "bs": true, "ca": true,
+	"cs": true, "cy": true, "da": true, "de": true, "el": true, "en": true, "eo": true, "es": true,
+	"et": true, "eu": true, "fa": true, "fi": true, "fr": true, "ga": true, "gl": true, "he": true,
+	"hi": true, "hr": true, "hu": true, "hy": true, "id": true, "is": true, "it": true, "ja": true,
+	"ka": true, "kk": true, "ko": true, "ky": true, "la": true, "lt": true, "lv": true, "mk": true,
+	"mn": true, "ms": true, "nl": true, "no": true, "pl": true, "pt": true, "ro": true, "ru": true,
+	"sk": true, "sl": true, "sq": true, "sr": true, "sv": true, "sw": true, "tg": true, "th": true,
+	"tl": true, "tr": true, "tt": true, "uk": true, "ur": true, "uz": true, "vi": true, "zh": true,
+}
+
+var (
+	translator     Translator
+	translatorOnce sync.Once
+)
+
+// GetTranslator returns translation backend from config
+func GetTranslator(conf *bot.Config) Translator {
+	translatorOnce.Do(func() {
+		switch conf.Translate.Provider {
+		case "libretranslate":
+			translator = &LibreTranslate{URL: conf.Translate.URL, APIKey: conf.Translate.APIKey}
+		case "yandex", "":
+			translator = &Yandex{APIKey: conf.Translate.APIKey, FolderID: conf.Translate.FolderID}
+		default:
+			fmt.Printf("Unknown translate provider \"%v\"\n", conf.Translate.Provider)
+		}
+	})
+	return translator
+}
+
+// ParseLanguages parses argument like "ru" or "en-ru" and returns source and target languages
+func ParseLanguages(arg string) (source, target string, ok bool) {
+	arg = strings.ToLower(arg)
+	if Languages[arg] {
+		return "", arg, true
+	}
+	langs := strings.Split(arg, "-")
+	if len(langs) == 2 && Languages[langs[0]] && Languages[langs[1]] {
+		return langs[0], langs[1], true
+	}
+	return "", "", false
+}
+
+// Translate translates text by configured backend
+func Translate(ctx context.Context, conf *bot.Config, text, source, target string) (*Result, error) {
+	t := GetTranslator(conf)
+	if t == nil {
+		return nil, ErrNotConfigured
+	}
+	return t.Translate(ctx, text, source, target)
+}
+
+// Detect returns language of text by configured backend
+func Detect(ctx context.Context, conf *bot.Config, text string) (string, error) {
+	t := GetTranslator(conf)
+	if t == nil {
+		return "", ErrNotConfigured
+	}
+	return t.Detect(ctx, text)
+}
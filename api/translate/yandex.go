@@ -0,0 +1,92 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+// Yandex Yandex Cloud Translate API v2 backend
+type Yandex struct {
+	APIKey   string
+	FolderID string
+}
+
+type yandexTranslateResponse struct {
+	Translations []struct {
+		Text                 string `json:"text"`
+		DetectedLanguageCode string `json:"detectedLanguageCode"`
+	} `json:"translations"`
+	Message string `json:"message"`
+}
+
+type yandexDetectResponse struct {
+	LanguageCode string `json:"languageCode"`
+	Message      string `json:"message"`
+}
+
+// Name returns backend name
+func (y *Yandex) Name() string {
+	return "yandex"
+}
+
+func (y *Yandex) post(ctx context.Context, method string, request map[string]interface{}, result interface{}) error {
+	if y.APIKey == "" {
+		return ErrNotConfigured
+	}
+	if y.FolderID != "" {
+		request["folderId"] = y.FolderID
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", "https://translate.api.cloud.yandex.net/translate/v2/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Api-Key "+y.APIKey)
+	body, err = httpclient.For("yandextranslate").Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, result)
+}
+
+// Translate translates text to target language
+func (y *Yandex) Translate(ctx context.Context, text, source, target string) (*Result, error) {
+	var result yandexTranslateResponse
+	request := map[string]interface{}{"texts": []string{text}, "targetLanguageCode": target}
+	if source != "" {
+		request["sourceLanguageCode"] = source
+	}
+	err := y.post(ctx, "translate", request, &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Translations) == 0 {
+		return nil, errors.New("empty translation")
+	}
+	if source == "" {
+		source = result.Translations[0].DetectedLanguageCode
+	}
+	return &Result{Text: result.Translations[0].Text, Source: source}, nil
+}
+
+// Detect returns language of text
+func (y *Yandex) Detect(ctx context.Context, text string) (string, error) {
+	var result yandexDetectResponse
+	err := y.post(ctx, "detect", map[string]interface{}{"text": text}, &result)
+	if err != nil {
+		return "", err
+	}
+	if result.LanguageCode == "" {
+		return "", errors.New("language not detected")
+	}
+	return result.LanguageCode, nil
+}
@@ -2,14 +2,12 @@ package weather
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"image/png"
-	"net/http"
 	"strings"
 	"time"
 
-	"github.com/FlameInTheDark/dtbot/api/location"
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 	"github.com/FlameInTheDark/dtbot/bot"
 	"github.com/fogleman/gg"
 )
@@ -76,32 +74,26 @@ func GetWeatherImage(ctx *bot.Context) (buf *bytes.Buffer, err error) {
 	)
 
 	if len(ctx.Args) > 0 {
-		city = strings.Join(ctx.Args, "+")
+		city = strings.Join(ctx.Args, " ")
 	}
 
-	loc, err := location.New(ctx.Conf.General.GeonamesUsername, city)
+	loc, err := ctx.Geocode(city)
 	if err != nil {
 		fmt.Printf("Location API: %v", err)
 		return
 	}
 
-	cityName := loc.Geonames[0].CountryName + ", " + loc.Geonames[0].Name
+	cityName := loc.Name
 
 	// Get coordinates and get weather data
-	newlat, newlng := loc.GetCoordinates()
-	resp, err := http.Get(fmt.Sprintf("https://api.openweathermap.org/data/2.5/forecast?lat=%v&lon=%v&lang=%v&units=metric&appid=%v",
-		newlat, newlng, ctx.Conf.General.Language, ctx.Conf.Weather.WeatherToken))
+	newlat, newlng := loc.Lat, loc.Lng
+	err = httpclient.For("openweathermap").GetJSON(ctx.CallContext(), fmt.Sprintf("https://api.openweathermap.org/data/2.5/forecast?lat=%v&lon=%v&lang=%v&units=metric&appid=%v",
+		newlat, newlng, ctx.Conf.General.Language, ctx.Conf.Weather.WeatherToken), &forecast)
 	if err != nil {
 		fmt.Printf("Weather API: %v", err)
 		return
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(&forecast)
-	if err != nil {
-		fmt.Printf("Weather Decode: %v", err)
-		return
-	}
-
 	gc := gg.NewContext(400, 650)
 	gc.SetRGBA(0, 0, 0, 0)
 	gc.Clear()
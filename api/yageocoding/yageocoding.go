@@ -1,13 +1,18 @@
 package yageocoding
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
+	"net/url"
 	"strings"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 )
 
+// ErrNotFound returns if location not found
+var ErrNotFound = errors.New("location not found")
+
 // YaGeoResponse contains response data
 type YaGeoResponse struct {
 	Response struct {
@@ -65,30 +70,42 @@ type YaGeoAddressComponent struct {
 	Name string `json:"name"`
 }
 
-// GetCoordinates returns coordinates
+// GetCoordinates returns latitude and longitude
 func (loc *YaGeoResponse) GetCoordinates() (string, string) {
 	if len(loc.Response.ObjectCollection.Member) == 0 {
 		return "0", "0"
 	}
 
+	// Yandex returns position as "longitude latitude"
 	str := strings.Split(loc.Response.ObjectCollection.Member[0].GeoObject.Point.Pos, " ")
-	return str[0], str[1]
+	if len(str) < 2 {
+		return "0", "0"
+	}
+	return str[1], str[0]
 }
 
-// GetData creates request to API and returns result
-func GetData(key, location string) (result YaGeoResponse, err error) {
-	resp, err := http.Get(fmt.Sprintf("https://geocode-maps.yandex.ru/1.x/?format=json&geocode=%v&apikey=%v", location, key))
-	if err != nil {
-		return
+// GetName returns description and name of first location
+func (loc *YaGeoResponse) GetName() string {
+	if len(loc.Response.ObjectCollection.Member) == 0 {
+		return ""
+	}
+	obj := loc.Response.ObjectCollection.Member[0].GeoObject
+	if obj.Description == "" {
+		return obj.Name
 	}
+	return obj.Description + ", " + obj.Name
+}
 
-	err = json.NewDecoder(resp.Body).Decode(&result)
+// GetData creates request to API and returns result
+func GetData(ctx context.Context, key, location string) (result YaGeoResponse, err error) {
+	err = httpclient.For("yandexgeocoder").GetJSON(ctx, fmt.Sprintf("https://geocode-maps.yandex.ru/1.x/?format=json&geocode=%v&apikey=%v",
+		url.QueryEscape(location), url.QueryEscape(key)), &result)
 	if err != nil {
 		return result, err
 	}
 
 	if len(result.Response.ObjectCollection.Member) == 0 {
-		return result, errors.New("location not fount")
+		return result, ErrNotFound
 	}
 	return
 }
@@ -4,10 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"github.com/FlameInTheDark/dtbot/api/yageocoding"
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 	"github.com/FlameInTheDark/dtbot/bot"
-	"io"
-	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 )
@@ -23,33 +22,25 @@ func GetMapImage(ctx *bot.Context) (buf *bytes.Buffer, err error) {
 	if len(ctx.Args) > 2 {
 		mapType = ctx.Args[0]
 		mapSize, _ = strconv.Atoi(ctx.Args[1])
-		city = strings.Join(ctx.Args[2:], "+")
+		city = strings.Join(ctx.Args[2:], " ")
 	}
 
 	if mapSize > 17 {
 		return buf, errors.New("map size out of range")
 	}
 
-	loc, err := yageocoding.GetData(ctx.Conf.General.GeocodingApiKey, city) //location.New(ctx.Conf.General.GeonamesUsername, city)
+	loc, err := ctx.Geocode(city)
 	if err != nil {
 		fmt.Printf("Location API: %v", err)
 		return
 	}
 
-	// Get coordinates and get weather data
-	newlat, newlng := loc.GetCoordinates()
-	resp, err := http.Get(fmt.Sprintf("https://static-maps.yandex.ru/1.x/?ll=%v,%v&size=450,450&z=%v&l=%v&pt=%v,%v,vkbkm",
-		newlat, newlng, mapSize, mapType, newlat, newlng))
+	// Static maps API takes coordinates as "longitude,latitude"
+	body, err := httpclient.For("yandexmap").Get(ctx.CallContext(), fmt.Sprintf("https://static-maps.yandex.ru/1.x/?ll=%v,%v&size=450,450&z=%v&l=%v&pt=%v,%v,vkbkm",
+		loc.Lng, loc.Lat, mapSize, url.QueryEscape(mapType), loc.Lng, loc.Lat))
 	if err != nil {
 		fmt.Printf("Map API: %v", err)
 		return
 	}
-
-	buf = new(bytes.Buffer)
-
-	_, err = io.Copy(buf, resp.Body) //png.Encode(buf, resp.Body)
-	if err != nil {
-		fmt.Printf("Map image: %v", err.Error())
-	}
-	return buf, err
+	return bytes.NewBuffer(body), nil
 }
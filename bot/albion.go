@@ -1,15 +1,18 @@
 package bot
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 	"github.com/bwmarrin/discordgo"
 	"net/http"
+	"net/url"
 	"strings"
-	"time"
+	"sync"
 )
 
+const albionAPIURL = "https://gameinfo.albiononline.com/api/gameinfo"
+
 // SearchResult contains search response
 type AlbionSearchResult struct {
 	Guilds  []AlbionGuildSearch  `json:"guilds"`
@@ -60,6 +63,8 @@ type AlbionPlayer struct {
 	KillFame         int             `json:"KillFame"`
 	FameRatio        float64         `json:"FameRatio"`
 	DamageDone       float64         `json:"DamageDone"`
+
+	LifetimeStatistics AlbionLifetimeStats `json:"LifetimeStatistics"`
 }
 
 // Equipment contains items in slots
@@ -100,31 +105,33 @@ type AlbionKill struct {
 	Type                 string         `json:"Type"`
 }
 
+// AlbionUpdater contains watched players and guilds, maps are guarded by mutex
 type AlbionUpdater struct {
+	sync.Mutex
+	// Players watched players by user and player ID
 	Players map[string]*AlbionPlayerUpdater
+	// Watches watched guilds and alliances by Discord channel and Albion ID
+	Watches        map[string]map[string]*AlbionWatch
+	PlayerNotifier *Notifier
+	GuildNotifier  *Notifier
 }
 
+// AlbionPlayerUpdater contains player watched by user, new kills are sent in private messages
 type AlbionPlayerUpdater struct {
 	PlayerID string
 	UserID   string
 	Language string
 	LastKill int64
 	StartAt  int64
+	Name     string
+	// Channel private channel of user
+	Channel string
 }
 
 // SearchPlayers returns player list by name
-func AlbionSearchPlayers(name string) (result *AlbionSearchResult, err error) {
+func AlbionSearchPlayers(ctx context.Context, name string) (result *AlbionSearchResult, err error) {
 	var sResult AlbionSearchResult
-	resp, err := http.Get(fmt.Sprintf("https://gameinfo.albiononline.com/api/gameinfo/search?q=%v", name))
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		return nil, errors.New("status " + resp.Status)
-	}
-
-	err = json.NewDecoder(resp.Body).Decode(&sResult)
+	err = httpclient.For("albion").GetJSON(ctx, fmt.Sprintf("%v/search?q=%v", albionAPIURL, url.QueryEscape(name)), &sResult)
 	if err != nil {
 		return nil, err
 	}
@@ -133,18 +140,9 @@ func AlbionSearchPlayers(name string) (result *AlbionSearchResult, err error) {
 }
 
 // GetPlayerKills returns array of kills by player id
-func AlbionGetPlayerKills(id string) (result []AlbionKill, err error) {
+func AlbionGetPlayerKills(ctx context.Context, id string) (result []AlbionKill, err error) {
 	var kills []AlbionKill
-	resp, err := http.Get(fmt.Sprintf("https://gameinfo.albiononline.com/api/gameinfo/players/%v/topkills?range=month&offset=0&limit=20", id))
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		return nil, errors.New("status " + resp.Status)
-	}
-
-	err = json.NewDecoder(resp.Body).Decode(&kills)
+	err = httpclient.For("albion").GetJSON(ctx, fmt.Sprintf("%v/players/%v/topkills?range=month&offset=0&limit=20", albionAPIURL, id), &kills)
 	if err != nil {
 		return nil, err
 	}
@@ -153,18 +151,9 @@ func AlbionGetPlayerKills(id string) (result []AlbionKill, err error) {
 }
 
 // AlbionGetKillID returns kill by ID
-func AlbionGetKillID(id string) (kill *AlbionKill, err error) {
+func AlbionGetKillID(ctx context.Context, id string) (kill *AlbionKill, err error) {
 	var result AlbionKill
-	resp, err := http.Get(fmt.Sprintf("https://gameinfo.albiononline.com/api/gameinfo/events/%v", id))
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		return nil, errors.New("status " + resp.Status)
-	}
-
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	err = httpclient.For("albion").GetJSON(ctx, fmt.Sprintf("%v/events/%v", albionAPIURL, id), &result)
 	if err != nil {
 		return nil, err
 	}
@@ -173,57 +162,34 @@ func AlbionGetKillID(id string) (kill *AlbionKill, err error) {
 }
 
 // ShowKills sends embed message in discord
-func (ctx *Context) AlbionShowKills() {
-	search, err := AlbionSearchPlayers(ctx.Args[1])
+func (ctx *Context) AlbionShowKills(name string) {
+	player := ctx.albionFindPlayer(name)
+	if player == nil {
+		return
+	}
+	kills, err := AlbionGetPlayerKills(ctx.CallContext(), player.ID)
 	if err != nil {
-		fmt.Println("Error:" + err.Error())
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Getting kills error: %v", err.Error()))
+		ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_error"))
 		return
 	}
-	fmt.Println("Founded players")
-	if len(search.Players) > 0 {
-		fmt.Println("Players more then 0")
-		kills, err := AlbionGetPlayerKills(search.Players[0].ID)
-		fmt.Println("Searching kills of " + search.Players[0].Name + search.Players[0].ID)
-		if err != nil {
-			fmt.Println("Error: " + err.Error())
-			return
-		}
-		fmt.Println("Founded kills of " + search.Players[0].Name)
-		if len(kills) > 0 {
-			fmt.Println("Kills more then 0")
-			embed := NewEmbed("Albion Killboard")
-			embed.Desc(fmt.Sprintf("[%v](https://albiononline.com/ru/killboard/player/%v)", search.Players[0].Name, search.Players[0].ID)) // "https://assets.albiononline.com/assets/images/icons/favicon.ico")
-			embed.Color(ctx.GuildConf().EmbedColor)
-			for _, k := range kills {
-				fmt.Println("Killed " + k.Victim.Name)
-				var timeString string
-				t, err := time.Parse(time.RFC3339Nano, k.TimeStamp)
-				if err == nil {
-					timeString = fmt.Sprintf("%v.%v.%v %v:%v", t.Day(), t.Month().String(), t.Year(), t.Hour(), t.Minute())
-				} else {
-					fmt.Println("Parse time: ", err.Error())
-				}
-				embed.Field(
-					k.Victim.Name,
-					fmt.Sprintf("%v [[%v](https://albiononline.com/ru/killboard/kill/%v)]",
-						fmt.Sprintf(ctx.Loc("albion_kill_short"),
-							k.Victim.DeathFame,
-							k.Victim.AverageItemPower,
-							timeString),
-						k.EventID,
-						k.EventID), false)
-			}
-			embed.Send(ctx)
-		}
-
+	if len(kills) == 0 {
+		ctx.ReplyEmbed("Albion Killboard", fmt.Sprintf(ctx.Loc("albion_no_kills"), player.Name))
+		return
 	}
+	ctx.albionKillsEmbed(player, kills, false).Send(ctx)
 }
 
 // AlbionShowKill sends kill embed to user
-func (ctx *Context) AlbionShowKill() {
-	kill, err := AlbionGetKillID(ctx.Args[1])
+func (ctx *Context) AlbionShowKill(id string) {
+	kill, err := AlbionGetKillID(ctx.CallContext(), id)
 	if err != nil {
-		fmt.Println("Error:" + err.Error())
+		if httpclient.IsStatus(err, http.StatusNotFound) {
+			ctx.ReplyEmbed("Albion Killboard", fmt.Sprintf(ctx.Loc("albion_kill_not_found"), id))
+		} else {
+			ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Getting kill error: %v", err.Error()))
+			ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_error"))
+		}
 		return
 	}
 
@@ -245,11 +211,16 @@ func (ctx *Context) AlbionShowKill() {
 		}
 		embed.Field(ctx.Loc("albion_participants"), strings.Join(names, ", "), true)
 	}
+	if card, err := AlbionKillCard(ctx.CallContext(), ctx.Conf, kill); err == nil {
+		embed.AttachImg("kill.png", card)
+	} else {
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Rendering kill card error: %v", err.Error()))
+	}
 	embed.Send(ctx)
 }
 
-// SendKill sends kill to user
-func SendKill(session *discordgo.Session, conf *Config, kill *AlbionKill, userID, lang string) {
+// albionKillEmbed makes embed of kill with kill card image
+func albionKillEmbed(ctx context.Context, conf *Config, kill *AlbionKill, lang string) *NewEmbedStruct {
 	embed := NewEmbed(fmt.Sprintf("Show on killboard #%v", kill.EventID))
 	embed.Desc(fmt.Sprintf("%v :crossed_swords: %v", kill.Killer.Name, kill.Victim.Name))
 	embed.Color(4460547)
@@ -273,21 +244,17 @@ func SendKill(session *discordgo.Session, conf *Config, kill *AlbionKill, userID
 			embed.Field(conf.GetLocaleLang("albion_participants", lang), participants, true)
 		}
 	}
-	ch, err := session.UserChannelCreate(userID)
-	if err != nil {
-		fmt.Println("Error whilst creating private channel, ", err.Error())
-		return
-	}
-	_, err = session.ChannelMessageSendEmbed(ch.ID, embed.GetEmbed())
-	if err != nil {
-		fmt.Println("Error whilst sending embed message, ", err.Error())
-		return
+	if card, err := AlbionKillCard(ctx, conf, kill); err == nil {
+		embed.AttachImg("kill.png", card)
+	} else {
+		fmt.Println("Error whilst rendering kill card, ", err.Error())
 	}
+	return embed
 }
 
 // GetPlayerByID returns player ID by player name
 func GetPlayerByName(name string) string {
-	search, err := AlbionSearchPlayers(name)
+	search, err := AlbionSearchPlayers(context.Background(), name)
 	if err == nil {
 		if len(search.Players) > 0 {
 			return search.Players[0].ID
@@ -297,118 +264,42 @@ func GetPlayerByName(name string) string {
 }
 
 // AlbionGetUpdater creates and returns albion kills updater
-func AlbionGetUpdater(db *DBWorker) *AlbionUpdater {
-	var updater = &AlbionUpdater{Players: make(map[string]*AlbionPlayerUpdater)}
+func AlbionGetUpdater(session *discordgo.Session, conf *Config, db *DBWorker) *AlbionUpdater {
+	var updater = &AlbionUpdater{
+		Players: make(map[string]*AlbionPlayerUpdater),
+		Watches: make(map[string]map[string]*AlbionWatch),
+	}
 	var players []AlbionPlayerUpdater
 	players = db.GetAlbionPlayers()
 	for i, p := range players {
-		updater.Players[p.UserID] = &players[i]
+		updater.Players[albionPlayerKey(p.UserID, p.PlayerID)] = &players[i]
 	}
-	return updater
-}
-
-// SendPlayerKills sends player kills
-func SendPlayerKills(session *discordgo.Session, worker *DBWorker, conf *Config, updater *AlbionUpdater, userID string) {
-	startTime := time.Unix(updater.Players[userID].StartAt, 0)
-	lastTime := time.Unix(updater.Players[userID].LastKill, 0)
-	if startTime.Add(time.Hour * 24).Unix() < time.Now().Unix() {
-		worker.RemoveAlbionPlayer(updater.Players[userID].UserID)
-		delete(updater.Players, updater.Players[userID].UserID)
-		return
-	} else {
-		kills, err := AlbionGetPlayerKills(updater.Players[userID].PlayerID)
-		if err != nil {
-			return
-		}
-		var newKillTime int64
-		for i, k := range kills {
-			killTime, err := time.Parse(time.RFC3339Nano, k.TimeStamp)
-			if err != nil {
-				fmt.Println("Kill time parse error: ", err.Error())
-				continue
-			}
-			if killTime.Unix() > lastTime.Unix() {
-				if killTime.Unix() > newKillTime {
-					newKillTime = killTime.Unix()
-				}
-				SendKill(session, conf, &kills[i], userID, updater.Players[userID].Language)
-			}
-		}
-		if newKillTime > lastTime.Unix() {
-			worker.UpdateAlbionPlayerLast(userID, newKillTime)
-			updater.Players[userID].LastKill = newKillTime
+	watches := db.GetAlbionWatches()
+	for i, w := range watches {
+		if updater.Watches[w.Channel] == nil {
+			updater.Watches[w.Channel] = make(map[string]*AlbionWatch)
 		}
+		updater.Watches[w.Channel][w.ID] = &watches[i]
 	}
-}
-
-// Update updates players kills and sends to users
-func (u *AlbionUpdater) Update(session *discordgo.Session, worker *DBWorker, conf *Config) {
-	for _, p := range u.Players {
-		startTime := time.Unix(p.StartAt, 0)
-		lastTime := time.Unix(p.LastKill, 0)
-		if startTime.Add(time.Hour * 24).Unix() < time.Now().Unix() {
-			worker.RemoveAlbionPlayer(p.UserID)
-			delete(u.Players, p.UserID)
-			return
-		} else {
-			kills, err := AlbionGetPlayerKills(p.PlayerID)
-			if err != nil {
-				worker.Log("albion", "", fmt.Sprintf("Getting player kills error: %v", err.Error()))
-				fmt.Println("Getting player kills error: ", err.Error())
-				return
-			}
-			var newKillTime int64
-			for i, k := range kills {
-				killTime, err := time.Parse(time.RFC3339Nano, k.TimeStamp)
-				if err != nil {
-					fmt.Println("Kill time parse error: ", err.Error())
-					worker.Log("albion", "", fmt.Sprintf("Parse time error: %v", err.Error()))
-					continue
-				}
-				if killTime.Unix() > lastTime.Unix() {
-					if killTime.Unix() > newKillTime {
-						newKillTime = killTime.Unix()
-					}
-					go SendKill(session, conf, &kills[i], p.UserID, p.Language)
-				}
-			}
-			if newKillTime > lastTime.Unix() {
-				worker.UpdateAlbionPlayerLast(p.UserID, newKillTime)
-				u.Players[p.UserID].LastKill = newKillTime
-			}
-		}
+	updater.PlayerNotifier = &Notifier{
+		Name:     "albion",
+		Interval: albionPlayerInterval,
+		Source:   &albionKillSource{u: updater, backoff: make(map[string]*albionBackoff)},
+		Detector: &albionKillDetector{updater},
+		Renderer: &albionKillRenderer{conf},
+		Store:    &albionPlayerStore{updater, session, conf, db},
+		Discord:  session,
+		DB:       db,
 	}
-}
-
-// AlbionAddPlayer adds player to updater
-func (ctx *Context) AlbionAddPlayer() error {
-	if len(ctx.Args) > 1 {
-		search, err := AlbionSearchPlayers(ctx.Args[1])
-		if err != nil {
-			ctx.Log("albion", "", fmt.Sprintf("Searching player error: %v", err.Error()))
-			return errors.New("error searching Albion player")
-		}
-		if _, ok := ctx.Albion.Players[ctx.User.ID]; !ok {
-			kills, err := AlbionGetPlayerKills(search.Players[0].ID)
-			if err != nil {
-				ctx.Log("albion", "", fmt.Sprintf("Getting kills error: %v", err.Error()))
-				return errors.New("error getting Albion kills")
-			}
-			var lastKill int64
-			for _, k := range kills {
-				killTime, err := time.Parse(time.RFC3339Nano, k.TimeStamp)
-				if err != nil {
-					continue
-				}
-				if killTime.Unix() > lastKill {
-					lastKill = killTime.Unix()
-				}
-			}
-			player := &AlbionPlayerUpdater{search.Players[0].ID, ctx.User.ID, ctx.GuildConf().Language, lastKill, time.Now().Unix()}
-			ctx.Albion.Players[ctx.User.ID] = player
-			ctx.DB.AddAlbionPlayer(player)
-			return nil
-		}
+	updater.GuildNotifier = &Notifier{
+		Name:     "albion",
+		Interval: albionWatchInterval,
+		Source:   &albionEventSource{},
+		Detector: &albionEventDetector{updater},
+		Renderer: &albionEventRenderer{conf},
+		Store:    &albionWatchStore{updater, db},
+		Discord:  session,
+		DB:       db,
 	}
-	return errors.New("error")
+	return updater
 }
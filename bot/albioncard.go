@@ -0,0 +1,235 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+	"github.com/fogleman/gg"
+)
+
+const (
+	albionRenderURL = "https://render.albiononline.com/v1/item/"
+	// albionIconSize size of icons requested from render service
+	albionIconSize = 217
+
+	albionCardWidth  = 800
+	albionCardHeight = 580
+	albionSlotSize   = 80
+	albionSlotGap    = 8
+)
+
+var albionIconName = regexp.MustCompile(`[^\w@-]`)
+
+// albionSlot position of equipment slot in grid of kill card
+type albionSlot struct {
+	col, row int
+	item     func(e *AlbionEquipment) AlbionItem
+}
+
+// albionSlots equipment slots in order of game character window
+var albionSlots = []albionSlot{
+	{0, 0, func(e *AlbionEquipment) AlbionItem { return e.Bag }},
+	{1, 0, func(e *AlbionEquipment) AlbionItem { return e.Head }},
+	{2, 0, func(e *AlbionEquipment) AlbionItem { return e.Cape }},
+	{0, 1, func(e *AlbionEquipment) AlbionItem { return e.MainHand }},
+	{1, 1, func(e *AlbionEquipment) AlbionItem { return e.Armor }},
+	{2, 1, func(e *AlbionEquipment) AlbionItem { return e.OffHand }},
+	{0, 2, func(e *AlbionEquipment) AlbionItem { return e.Potion }},
+	{1, 2, func(e *AlbionEquipment) AlbionItem { return e.Shoes }},
+	{2, 2, func(e *AlbionEquipment) AlbionItem { return e.Food }},
+	{1, 3, func(e *AlbionEquipment) AlbionItem { return e.Mount }},
+}
+
+// albionIconPath returns path of cached icon of item
+func albionIconPath(conf *Config, item AlbionItem) string {
+	dir := conf.Albion.IconsDir
+	if dir == "" {
+		dir = "albion_icons"
+	}
+	quality := item.Quality
+	if quality == 0 {
+		quality = 1
+	}
+	return filepath.Join(dir, fmt.Sprintf("%v_q%v.png", albionIconName.ReplaceAllString(item.Type, "_"), quality))
+}
+
+// AlbionItemIcon returns icon of item from disk cache or Albion render service
+func AlbionItemIcon(ctx context.Context, conf *Config, item AlbionItem) (image.Image, error) {
+	path := albionIconPath(conf, item)
+	if file, err := os.Open(path); err == nil {
+		img, err := png.Decode(file)
+		file.Close()
+		if err == nil {
+			return img, nil
+		}
+	}
+
+	quality := item.Quality
+	if quality == 0 {
+		quality = 1
+	}
+	body, err := httpclient.For("images").Get(ctx, fmt.Sprintf("%v%v.png?quality=%v&size=%v", albionRenderURL, item.Type, quality, albionIconSize))
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		// Icon is renamed after writing, so concurrent renders never read partial file
+		if tmp, err := ioutil.TempFile(filepath.Dir(path), "icon"); err == nil {
+			_, werr := tmp.Write(body)
+			tmp.Close()
+			if werr != nil || os.Rename(tmp.Name(), path) != nil {
+				_ = os.Remove(tmp.Name())
+			}
+		}
+	}
+	return img, nil
+}
+
+// albionLoadIcons loads icons of equipment of killer and victim concurrently, failed icons are missing in result
+func albionLoadIcons(ctx context.Context, conf *Config, kill *AlbionKill) map[string]image.Image {
+	var (
+		icons = make(map[string]image.Image)
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+	)
+	for _, e := range []*AlbionEquipment{&kill.Killer.Equipment, &kill.Victim.Equipment} {
+		for _, slot := range albionSlots {
+			item := slot.item(e)
+			if item.Type == "" {
+				continue
+			}
+			path := albionIconPath(conf, item)
+			mu.Lock()
+			_, loading := icons[path]
+			icons[path] = nil
+			mu.Unlock()
+			if loading {
+				continue
+			}
+			wg.Add(1)
+			go func(item AlbionItem, path string) {
+				defer wg.Done()
+				img, err := AlbionItemIcon(ctx, conf, item)
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				icons[path] = img
+				mu.Unlock()
+			}(item, path)
+		}
+	}
+	wg.Wait()
+	return icons
+}
+
+// AlbionKillCard renders image of kill with equipment of killer and victim
+func AlbionKillCard(ctx context.Context, conf *Config, kill *AlbionKill) (*bytes.Buffer, error) {
+	icons := albionLoadIcons(ctx, conf, kill)
+
+	gc := gg.NewContext(albionCardWidth, albionCardHeight)
+	gc.SetRGBA(0, 0, 0, 0)
+	gc.Clear()
+	gc.SetRGB255(36, 38, 46)
+	gc.DrawRoundedRectangle(0, 0, albionCardWidth, albionCardHeight, 10)
+	gc.Fill()
+
+	if err := gc.LoadFontFace("lato.ttf", 16); err != nil {
+		return nil, err
+	}
+	gc.SetRGBA(1, 1, 1, 0.4)
+	gc.DrawStringAnchored(fmt.Sprintf("#%v", kill.EventID), albionCardWidth/2, 30, 0.5, 0.5)
+	gc.DrawStringAnchored("vs", albionCardWidth/2, 60, 0.5, 0.5)
+
+	albionDrawPlayer(gc, icons, conf, &kill.Killer, 0, 5025616)
+	albionDrawPlayer(gc, icons, conf, &kill.Victim, albionCardWidth/2, 13632027)
+
+	// Footer
+	if err := gc.LoadFontFace("lato.ttf", 20); err != nil {
+		return nil, err
+	}
+	gc.SetRGBA(1, 1, 1, 0.8)
+	gc.DrawStringAnchored(fmt.Sprintf("Fame: %v", kill.TotalVictimKillFame), albionCardWidth/2, 510, 0.5, 0.5)
+	if len(kill.Participants) > 0 {
+		if err := gc.LoadFontFace("lato.ttf", 14); err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, p := range kill.Participants {
+			names = append(names, fmt.Sprintf("%v (%.0f)", p.Name, p.DamageDone))
+		}
+		line := strings.Join(names, ", ")
+		for utf8.RuneCountInString(line) > 3 {
+			if w, _ := gc.MeasureString(line); w <= albionCardWidth-40 {
+				break
+			}
+			line = TruncateText(line, utf8.RuneCountInString(line)-1)
+		}
+		gc.SetRGBA(1, 1, 1, 0.5)
+		gc.DrawStringAnchored(line, albionCardWidth/2, 545, 0.5, 0.5)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, gc.Image()); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// albionDrawPlayer draws name, guild, item power and equipment of player in half of card
+func albionDrawPlayer(gc *gg.Context, icons map[string]image.Image, conf *Config, player *AlbionPlayer, x float64, color int) {
+	center := x + albionCardWidth/4
+	_ = gc.LoadFontFace("lato.ttf", 26)
+	gc.SetRGB255(color>>16&0xff, color>>8&0xff, color&0xff)
+	gc.DrawStringAnchored(player.Name, center, 30, 0.5, 0.5)
+
+	_ = gc.LoadFontFace("lato.ttf", 16)
+	gc.SetRGBA(1, 1, 1, 0.6)
+	guild := player.GuildName
+	if player.AllianceName != "" {
+		guild = fmt.Sprintf("[%v] %v", player.AllianceName, guild)
+	}
+	gc.DrawStringAnchored(guild, center, 60, 0.5, 0.5)
+	gc.DrawStringAnchored(fmt.Sprintf("IP: %.0f", player.AverageItemPower), center, 85, 0.5, 0.5)
+
+	gridWidth := 3*albionSlotSize + 2*albionSlotGap
+	left := center - float64(gridWidth)/2
+	for _, slot := range albionSlots {
+		item := slot.item(&player.Equipment)
+		sx := left + float64(slot.col*(albionSlotSize+albionSlotGap))
+		sy := 110 + float64(slot.row*(albionSlotSize+albionSlotGap))
+		gc.SetRGBA(1, 1, 1, 0.05)
+		gc.DrawRoundedRectangle(sx, sy, albionSlotSize, albionSlotSize, 6)
+		gc.Fill()
+		if item.Type == "" {
+			continue
+		}
+		if img := icons[albionIconPath(conf, item)]; img != nil {
+			scale := float64(albionSlotSize) / float64(img.Bounds().Dx())
+			gc.Push()
+			gc.Translate(sx, sy)
+			gc.Scale(scale, scale)
+			gc.DrawImage(img, 0, 0)
+			gc.Pop()
+		}
+		if item.Count > 1 {
+			gc.SetRGBA(1, 1, 1, 0.9)
+			gc.DrawStringAnchored(fmt.Sprintf("%v", item.Count), sx+albionSlotSize-6, sy+albionSlotSize-10, 1, 0.5)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// albionPlayerInterval interval between checks of watched players
+	albionPlayerInterval = time.Minute
+	// albionMaxBackoff max delay of player checks after API errors
+	albionMaxBackoff = 30 * time.Minute
+	// albionMaxKills max count of kills sent to user per check
+	albionMaxKills = 5
+	// albionMaxPlayers max count of players watched by one user
+	albionMaxPlayers = 5
+)
+
+// albionPlayerKey returns key of watched player in updater
+func albionPlayerKey(userID, playerID string) string {
+	return userID + ":" + playerID
+}
+
+// Subject returns Albion player ID
+func (p *AlbionPlayerUpdater) Subject() string {
+	return p.PlayerID
+}
+
+// Target returns private channel of user
+func (p *AlbionPlayerUpdater) Target() string {
+	return p.Channel
+}
+
+// Expires returns end time of watch
+func (p *AlbionPlayerUpdater) Expires(conf *Config) time.Time {
+	hours := conf.Albion.WatchHours
+	if hours <= 0 {
+		hours = 24
+	}
+	return time.Unix(p.StartAt, 0).Add(time.Duration(hours) * time.Hour)
+}
+
+// AlbionGetPlayerLatestKills returns latest kills of player
+func AlbionGetPlayerLatestKills(ctx context.Context, id string) ([]AlbionKill, error) {
+	var kills []AlbionKill
+	err := httpclient.For("albion").GetJSON(ctx, fmt.Sprintf("%v/players/%v/kills", albionAPIURL, id), &kills)
+	if err != nil {
+		return nil, err
+	}
+	return kills, nil
+}
+
+// albionBackoff contains delay of player checks after API errors
+type albionBackoff struct {
+	failures int
+	next     time.Time
+}
+
+// albionKillSource polls latest kills of watched players
+type albionKillSource struct {
+	u *AlbionUpdater
+	// backoff delays by player ID, accessed only in notifier checks
+	backoff map[string]*albionBackoff
+}
+
+// Poll returns latest kills of players, players with API errors are skipped and checked later
+func (src *albionKillSource) Poll(ctx context.Context, subjects []string) (map[string][]NotifyItem, error) {
+	src.pruneBackoff()
+	var items = make(map[string][]NotifyItem)
+	for _, id := range subjects {
+		kills, err := AlbionGetPlayerLatestKills(ctx, id)
+		if err != nil {
+			b := src.backoff[id]
+			if b == nil {
+				b = &albionBackoff{}
+				src.backoff[id] = b
+			}
+			b.failures++
+			delay := albionPlayerInterval << uint(b.failures)
+			if delay > albionMaxBackoff || delay <= 0 {
+				delay = albionMaxBackoff
+			}
+			b.next = time.Now().Add(delay)
+			fmt.Printf("Getting Albion player [%v] kills error: %v\n", id, err)
+			continue
+		}
+		delete(src.backoff, id)
+		var killItems = make([]NotifyItem, 0, len(kills))
+		for i, k := range kills {
+			killItems = append(killItems, NotifyItem{ID: fmt.Sprintf("%v", k.EventID), Data: &kills[i]})
+		}
+		items[id] = killItems
+	}
+	return items, nil
+}
+
+// pruneBackoff removes delays of players that are not watched anymore.
+// Delayed players are not in polled subjects, so watched players are taken from updater
+func (src *albionKillSource) pruneBackoff() {
+	var watched = make(map[string]bool)
+	src.u.Lock()
+	for _, p := range src.u.Players {
+		watched[p.PlayerID] = true
+	}
+	src.u.Unlock()
+	for id := range src.backoff {
+		if !watched[id] {
+			delete(src.backoff, id)
+		}
+	}
+}
+
+// Watch skips players delayed after API errors
+func (src *albionKillSource) Watch(sub NotifySubscription) bool {
+	b := src.backoff[sub.Subject()]
+	return b == nil || !time.Now().Before(b.next)
+}
+
+// albionKillDetector finds kills made after last sent kill
+type albionKillDetector struct {
+	u *AlbionUpdater
+}
+
+// Detect returns new kills of player, older kills first
+func (d *albionKillDetector) Detect(subject string, subs []NotifySubscription, items []NotifyItem) []NotifyEvent {
+	type kill struct {
+		item NotifyItem
+		time int64
+	}
+	var kills []kill
+	for _, item := range items {
+		t, err := time.Parse(time.RFC3339Nano, item.Data.(*AlbionKill).TimeStamp)
+		if err != nil {
+			continue
+		}
+		kills = append(kills, kill{item, t.Unix()})
+	}
+	sort.Slice(kills, func(i, j int) bool { return kills[i].time < kills[j].time })
+
+	d.u.Lock()
+	defer d.u.Unlock()
+	var events []NotifyEvent
+	for _, sub := range subs {
+		p := sub.(*AlbionPlayerUpdater)
+		var fresh []kill
+		for _, k := range kills {
+			if k.time > p.LastKill {
+				fresh = append(fresh, k)
+			}
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+		p.LastKill = fresh[len(fresh)-1].time
+		if len(fresh) > albionMaxKills {
+			fresh = fresh[len(fresh)-albionMaxKills:]
+		}
+		for _, k := range fresh {
+			events = append(events, NotifyEvent{Type: NotifyStarted, Subscription: p, Item: k.item})
+		}
+	}
+	return events
+}
+
+// albionKillRenderer makes kill messages of watched players
+type albionKillRenderer struct {
+	conf *Config
+}
+
+// Render makes kill embed with kill card
+func (r *albionKillRenderer) Render(ctx context.Context, event NotifyEvent) *NewEmbedStruct {
+	return albionKillEmbed(ctx, r.conf, event.Item.Data.(*AlbionKill), event.Subscription.(*AlbionPlayerUpdater).Language)
+}
+
+// albionPlayerStore keeps watched players in memory and MongoDB
+type albionPlayerStore struct {
+	u       *AlbionUpdater
+	discord *discordgo.Session
+	conf    *Config
+	db      *DBWorker
+}
+
+// Subscriptions removes expired watches and returns active ones
+func (st *albionPlayerStore) Subscriptions() []NotifySubscription {
+	var (
+		expired  []*AlbionPlayerUpdater
+		channels []*AlbionPlayerUpdater
+		subs     []NotifySubscription
+		now      = time.Now()
+	)
+	st.u.Lock()
+	for key, p := range st.u.Players {
+		if p.Expires(st.conf).Before(now) {
+			expired = append(expired, p)
+			delete(st.u.Players, key)
+			continue
+		}
+		if p.Channel == "" {
+			channels = append(channels, p)
+			continue
+		}
+		subs = append(subs, p)
+	}
+	st.u.Unlock()
+
+	for _, p := range expired {
+		st.db.RemoveAlbionPlayer(p.UserID, p.PlayerID)
+	}
+	// Watches made before private channels were saved
+	for _, p := range channels {
+		ch, err := st.discord.UserChannelCreate(p.UserID)
+		if err != nil {
+			continue
+		}
+		st.u.Lock()
+		p.Channel = ch.ID
+		st.u.Unlock()
+		st.db.UpdateAlbionPlayerChannel(p)
+		subs = append(subs, p)
+	}
+	return subs
+}
+
+// Save saves time of last sent kill
+func (st *albionPlayerStore) Save(event NotifyEvent, messageID string) {
+	p := event.Subscription.(*AlbionPlayerUpdater)
+	st.u.Lock()
+	lastKill := p.LastKill
+	st.u.Unlock()
+	st.db.UpdateAlbionPlayerLast(p.UserID, p.PlayerID, lastKill)
+}
+
+// AlbionAddPlayer adds player to updater and returns player name
+func (ctx *Context) AlbionAddPlayer(name string) (string, error) {
+	search, err := AlbionSearchPlayers(ctx.CallContext(), name)
+	if err != nil {
+		ctx.Log("albion", "", fmt.Sprintf("Searching player error: %v", err.Error()))
+		return "", errors.New("error searching Albion player")
+	}
+	if len(search.Players) == 0 {
+		return "", errors.New("albion player not found")
+	}
+	found := search.Players[0]
+	for _, p := range search.Players {
+		if strings.EqualFold(p.Name, name) {
+			found = p
+			break
+		}
+	}
+
+	ctx.Albion.Lock()
+	err = ctx.Albion.checkNewPlayer(ctx.User.ID, found.ID)
+	ctx.Albion.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	kills, err := AlbionGetPlayerLatestKills(ctx.CallContext(), found.ID)
+	if err != nil {
+		ctx.Log("albion", "", fmt.Sprintf("Getting kills error: %v", err.Error()))
+		return "", errors.New("error getting Albion kills")
+	}
+	var lastKill int64
+	for _, k := range kills {
+		killTime, err := time.Parse(time.RFC3339Nano, k.TimeStamp)
+		if err != nil {
+			continue
+		}
+		if killTime.Unix() > lastKill {
+			lastKill = killTime.Unix()
+		}
+	}
+	ch, err := ctx.Discord.UserChannelCreate(ctx.User.ID)
+	if err != nil {
+		return "", errors.New("error creating private channel")
+	}
+	player := &AlbionPlayerUpdater{
+		PlayerID: found.ID,
+		UserID:   ctx.User.ID,
+		Language: ctx.GuildConf().Language,
+		LastKill: lastKill,
+		StartAt:  time.Now().Unix(),
+		Name:     found.Name,
+		Channel:  ch.ID,
+	}
+	ctx.Albion.Lock()
+	// Players may be added by another command while kills were requested
+	if err := ctx.Albion.checkNewPlayer(player.UserID, player.PlayerID); err != nil {
+		ctx.Albion.Unlock()
+		return "", err
+	}
+	ctx.Albion.Players[albionPlayerKey(player.UserID, player.PlayerID)] = player
+	ctx.Albion.Unlock()
+	ctx.DB.AddAlbionPlayer(player)
+	return player.Name, nil
+}
+
+// checkNewPlayer returns error if player is already watched by user or user watches too many players,
+// updater must be locked
+func (u *AlbionUpdater) checkNewPlayer(userID, playerID string) error {
+	if _, exists := u.Players[albionPlayerKey(userID, playerID)]; exists {
+		return errors.New("player already watched")
+	}
+	var count int
+	for _, p := range u.Players {
+		if p.UserID == userID {
+			count++
+		}
+	}
+	if count >= albionMaxPlayers {
+		return errors.New("too many watched players")
+	}
+	return nil
+}
+
+// AlbionRemovePlayer removes watched player of user by name, all players if name is empty.
+// Returns count of removed players
+func (ctx *Context) AlbionRemovePlayer(name string) int {
+	var removed []*AlbionPlayerUpdater
+	ctx.Albion.Lock()
+	for key, p := range ctx.Albion.Players {
+		if p.UserID == ctx.User.ID && (name == "" || strings.EqualFold(p.Name, name) || p.PlayerID == name) {
+			removed = append(removed, p)
+			delete(ctx.Albion.Players, key)
+		}
+	}
+	ctx.Albion.Unlock()
+	for _, p := range removed {
+		ctx.DB.RemoveAlbionPlayer(p.UserID, p.PlayerID)
+	}
+	return len(removed)
+}
+
+// AlbionShowPlayers sends watched players of user with remaining time
+func (ctx *Context) AlbionShowPlayers() {
+	var lines []string
+	ctx.Albion.Lock()
+	for _, p := range ctx.Albion.Players {
+		if p.UserID == ctx.User.ID {
+			name := p.Name
+			if name == "" {
+				name = p.PlayerID
+			}
+			lines = append(lines, fmt.Sprintf(ctx.Loc("albion_list_line"), name, formatStreamDuration(time.Until(p.Expires(ctx.Conf)))))
+		}
+	}
+	ctx.Albion.Unlock()
+	if len(lines) == 0 {
+		ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_not_watching"))
+		return
+	}
+	sort.Strings(lines)
+	ctx.ReplyEmbed("Albion Killboard", strings.Join(lines, "\n"))
+}
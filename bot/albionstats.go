@@ -0,0 +1,349 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+const (
+	// albionMarketURL default Albion Data Project server
+	albionMarketURL = "https://west.albion-online-data.com"
+	// albionItemsURL list of item IDs with english names
+	albionItemsURL = "https://raw.githubusercontent.com/ao-data/ao-bin-dumps/master/formatted/items.txt"
+	albionMarkets  = "Caerleon,Bridgewatch,Fort Sterling,Lymhurst,Martlock,Thetford,Brecilien,Black Market"
+)
+
+var albionItemID = regexp.MustCompile(`^T\d_[A-Z0-9_]+(@\d)?$`)
+
+// AlbionLifetimeStats contains fame of player by activity
+type AlbionLifetimeStats struct {
+	PvE struct {
+		Total int `json:"Total"`
+	} `json:"PvE"`
+	Gathering struct {
+		All struct {
+			Total int `json:"Total"`
+		} `json:"All"`
+	} `json:"Gathering"`
+	Crafting struct {
+		Total int `json:"Total"`
+	} `json:"Crafting"`
+	FishingFame int `json:"FishingFame"`
+	FarmingFame int `json:"FarmingFame"`
+}
+
+// AlbionGuild contains guild data
+type AlbionGuild struct {
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	FounderName  string `json:"FounderName"`
+	Founded      string `json:"Founded"`
+	AllianceTag  string `json:"AllianceTag"`
+	AllianceName string `json:"AllianceName"`
+	KillFame     int    `json:"killFame"`
+	DeathFame    int    `json:"DeathFame"`
+	MemberCount  int    `json:"MemberCount"`
+}
+
+// AlbionPrice contains market price of item in city
+type AlbionPrice struct {
+	ItemID       string `json:"item_id"`
+	City         string `json:"city"`
+	Quality      int    `json:"quality"`
+	SellPriceMin int    `json:"sell_price_min"`
+	BuyPriceMax  int    `json:"buy_price_max"`
+}
+
+type albionItemName struct {
+	ID   string
+	Name string
+}
+
+// albionItems list of items, loaded on first search
+var albionItems struct {
+	sync.Mutex
+	list []albionItemName
+}
+
+// AlbionGetPlayer returns player by ID
+func AlbionGetPlayer(ctx context.Context, id string) (*AlbionPlayer, error) {
+	var player AlbionPlayer
+	err := httpclient.For("albion").GetJSON(ctx, fmt.Sprintf("%v/players/%v", albionAPIURL, id), &player)
+	if err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
+
+// AlbionGetPlayerDeaths returns last deaths of player
+func AlbionGetPlayerDeaths(ctx context.Context, id string) ([]AlbionKill, error) {
+	var deaths []AlbionKill
+	err := httpclient.For("albion").GetJSON(ctx, fmt.Sprintf("%v/players/%v/deaths", albionAPIURL, id), &deaths)
+	if err != nil {
+		return nil, err
+	}
+	return deaths, nil
+}
+
+// AlbionGetGuild returns guild by ID
+func AlbionGetGuild(ctx context.Context, id string) (*AlbionGuild, error) {
+	var guild AlbionGuild
+	err := httpclient.For("albion").GetJSON(ctx, fmt.Sprintf("%v/guilds/%v", albionAPIURL, id), &guild)
+	if err != nil {
+		return nil, err
+	}
+	return &guild, nil
+}
+
+// AlbionGetPrices returns current prices of item from Albion Data Project
+func AlbionGetPrices(ctx context.Context, marketURL, itemID string) ([]AlbionPrice, error) {
+	if marketURL == "" {
+		marketURL = albionMarketURL
+	}
+	var prices []AlbionPrice
+	err := httpclient.For("albion").GetJSON(ctx, fmt.Sprintf("%v/api/v2/stats/prices/%v.json?%v",
+		strings.TrimSuffix(marketURL, "/"), url.PathEscape(itemID), url.Values{"locations": {albionMarkets}}.Encode()), &prices)
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// AlbionFindItem returns ID and name of item by ID or english name, exact name is preferred
+func AlbionFindItem(ctx context.Context, query string) (string, string, error) {
+	if albionItemID.MatchString(strings.ToUpper(query)) {
+		return strings.ToUpper(query), strings.ToUpper(query), nil
+	}
+	albionItems.Lock()
+	defer albionItems.Unlock()
+	if albionItems.list == nil {
+		body, err := httpclient.For("albion").Get(ctx, albionItemsURL)
+		if err != nil {
+			return "", "", err
+		}
+		// Lines look like "  12: T4_BAG   : Adept's Bag"
+		for _, line := range strings.Split(string(body), "\n") {
+			parts := strings.SplitN(line, ":", 3)
+			if len(parts) < 3 {
+				continue
+			}
+			albionItems.list = append(albionItems.list, albionItemName{
+				ID:   strings.TrimSpace(parts[1]),
+				Name: strings.TrimSpace(parts[2]),
+			})
+		}
+	}
+	var found *albionItemName
+	for i, item := range albionItems.list {
+		if strings.EqualFold(item.Name, query) {
+			return item.ID, item.Name, nil
+		}
+		if found == nil && item.Name != "" && strings.Contains(strings.ToLower(item.Name), strings.ToLower(query)) {
+			found = &albionItems.list[i]
+		}
+	}
+	if found == nil {
+		return "", "", errors.New("item not found")
+	}
+	return found.ID, found.Name, nil
+}
+
+// albionFindPlayer returns player by name and replies if player not found
+func (ctx *Context) albionFindPlayer(name string) *AlbionPlayerSearch {
+	search, err := AlbionSearchPlayers(ctx.CallContext(), name)
+	if err != nil {
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Searching player error: %v", err.Error()))
+		ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_error"))
+		return nil
+	}
+	if len(search.Players) == 0 {
+		ctx.ReplyEmbed("Albion Killboard", fmt.Sprintf(ctx.Loc("albion_player_not_found"), name))
+		return nil
+	}
+	for i, p := range search.Players {
+		if strings.EqualFold(p.Name, name) {
+			return &search.Players[i]
+		}
+	}
+	return &search.Players[0]
+}
+
+// albionKillsEmbed makes list of kills, deaths list shows killers
+func (ctx *Context) albionKillsEmbed(player *AlbionPlayerSearch, kills []AlbionKill, deaths bool) *NewEmbedStruct {
+	embed := NewEmbed("Albion Killboard")
+	embed.Desc(fmt.Sprintf("[%v](https://albiononline.com/ru/killboard/player/%v)", player.Name, player.ID))
+	embed.Color(ctx.GuildConf().EmbedColor)
+	for _, k := range kills {
+		var timeString string
+		if t, err := time.Parse(time.RFC3339Nano, k.TimeStamp); err == nil {
+			timeString = fmt.Sprintf("%v.%v.%v %v:%v", t.Day(), t.Month().String(), t.Year(), t.Hour(), t.Minute())
+		}
+		name, power := k.Victim.Name, k.Victim.AverageItemPower
+		if deaths {
+			name, power = k.Killer.Name, k.Killer.AverageItemPower
+		}
+		embed.Field(
+			name,
+			fmt.Sprintf("%v [[%v](https://albiononline.com/ru/killboard/kill/%v)]",
+				fmt.Sprintf(ctx.Loc("albion_kill_short"), k.TotalVictimKillFame, power, timeString),
+				k.EventID,
+				k.EventID), false)
+	}
+	return embed
+}
+
+// AlbionShowDeaths sends last deaths of player
+func (ctx *Context) AlbionShowDeaths(name string) {
+	player := ctx.albionFindPlayer(name)
+	if player == nil {
+		return
+	}
+	deaths, err := AlbionGetPlayerDeaths(ctx.CallContext(), player.ID)
+	if err != nil {
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Getting deaths error: %v", err.Error()))
+		ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_error"))
+		return
+	}
+	if len(deaths) == 0 {
+		ctx.ReplyEmbed("Albion Killboard", fmt.Sprintf(ctx.Loc("albion_no_deaths"), player.Name))
+		return
+	}
+	if len(deaths) > 10 {
+		deaths = deaths[:10]
+	}
+	ctx.albionKillsEmbed(player, deaths, true).Send(ctx)
+}
+
+// AlbionShowPlayer sends fame statistics of player
+func (ctx *Context) AlbionShowPlayer(name string) {
+	found := ctx.albionFindPlayer(name)
+	if found == nil {
+		return
+	}
+	player, err := AlbionGetPlayer(ctx.CallContext(), found.ID)
+	if err != nil {
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Getting player error: %v", err.Error()))
+		ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_error"))
+		return
+	}
+	stats := player.LifetimeStatistics
+	embed := NewEmbed(player.Name).
+		URL(fmt.Sprintf("https://albiononline.com/ru/killboard/player/%v", player.Id)).
+		Author("Albion Killboard", "https://albiononline.com/ru/killboard", "").
+		Color(ctx.GuildConf().EmbedColor)
+	if player.GuildName != "" {
+		guild := player.GuildName
+		if player.AllianceName != "" {
+			guild = fmt.Sprintf("[%v] %v", player.AllianceName, guild)
+		}
+		embed.Field(ctx.Loc("albion_guild"), guild, false)
+	}
+	embed.Field(ctx.Loc("albion_kill_fame"), fmt.Sprintf("%d", player.KillFame), true)
+	embed.Field(ctx.Loc("albion_death_fame"), fmt.Sprintf("%d", player.DeathFame), true)
+	embed.Field(ctx.Loc("albion_fame_ratio"), fmt.Sprintf("%.2f", player.FameRatio), true)
+	embed.Field(ctx.Loc("albion_pve_fame"), fmt.Sprintf("%d", stats.PvE.Total), true)
+	embed.Field(ctx.Loc("albion_gathering_fame"), fmt.Sprintf("%d", stats.Gathering.All.Total), true)
+	embed.Field(ctx.Loc("albion_crafting_fame"), fmt.Sprintf("%d", stats.Crafting.Total), true)
+	embed.Send(ctx)
+}
+
+// AlbionShowGuild sends guild information
+func (ctx *Context) AlbionShowGuild(name string) {
+	search, err := AlbionSearchPlayers(ctx.CallContext(), name)
+	if err != nil {
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Searching guild error: %v", err.Error()))
+		ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_error"))
+		return
+	}
+	if len(search.Guilds) == 0 {
+		ctx.ReplyEmbed("Albion Killboard", fmt.Sprintf(ctx.Loc("albion_guild_not_found"), name))
+		return
+	}
+	id := search.Guilds[0].ID
+	for _, g := range search.Guilds {
+		if strings.EqualFold(g.Name, name) {
+			id = g.ID
+			break
+		}
+	}
+	guild, err := AlbionGetGuild(ctx.CallContext(), id)
+	if err != nil {
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Getting guild error: %v", err.Error()))
+		ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_error"))
+		return
+	}
+	embed := NewEmbed(guild.Name).
+		URL(fmt.Sprintf("https://albiononline.com/ru/killboard/guild/%v", guild.ID)).
+		Author("Albion Killboard", "https://albiononline.com/ru/killboard", "").
+		Color(ctx.GuildConf().EmbedColor)
+	if guild.AllianceName != "" {
+		embed.Field(ctx.Loc("albion_alliance"), fmt.Sprintf("[%v] %v", guild.AllianceTag, guild.AllianceName), false)
+	}
+	embed.Field(ctx.Loc("albion_founder"), guild.FounderName, true)
+	if founded, err := time.Parse(time.RFC3339Nano, guild.Founded); err == nil {
+		embed.Field(ctx.Loc("albion_founded"), founded.Format("02.01.2006"), true)
+	}
+	embed.Field(ctx.Loc("albion_members"), fmt.Sprintf("%d", guild.MemberCount), true)
+	embed.Field(ctx.Loc("albion_kill_fame"), fmt.Sprintf("%d", guild.KillFame), true)
+	embed.Field(ctx.Loc("albion_death_fame"), fmt.Sprintf("%d", guild.DeathFame), true)
+	embed.Send(ctx)
+}
+
+// AlbionShowPrice sends lowest sell and highest buy orders of item in cities
+func (ctx *Context) AlbionShowPrice(query string) {
+	itemID, itemName, err := AlbionFindItem(ctx.CallContext(), query)
+	if err != nil {
+		ctx.ReplyEmbed("Albion Market", fmt.Sprintf(ctx.Loc("albion_item_not_found"), query))
+		return
+	}
+	prices, err := AlbionGetPrices(ctx.CallContext(), ctx.Conf.Albion.MarketURL, itemID)
+	if err != nil {
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Getting prices error: %v", err.Error()))
+		ctx.ReplyEmbed("Albion Market", ctx.Loc("albion_error"))
+		return
+	}
+	// Prices of all qualities are merged by city
+	var (
+		cities  []string
+		sellMin = make(map[string]int)
+		buyMax  = make(map[string]int)
+	)
+	for _, p := range prices {
+		if p.SellPriceMin == 0 && p.BuyPriceMax == 0 {
+			continue
+		}
+		if _, ok := sellMin[p.City]; !ok {
+			cities = append(cities, p.City)
+			sellMin[p.City] = 0
+		}
+		if p.SellPriceMin > 0 && (sellMin[p.City] == 0 || p.SellPriceMin < sellMin[p.City]) {
+			sellMin[p.City] = p.SellPriceMin
+		}
+		if p.BuyPriceMax > buyMax[p.City] {
+			buyMax[p.City] = p.BuyPriceMax
+		}
+	}
+	if len(cities) == 0 {
+		ctx.ReplyEmbed("Albion Market", fmt.Sprintf(ctx.Loc("albion_no_prices"), itemName))
+		return
+	}
+	sort.Strings(cities)
+	embed := NewEmbed(itemName).
+		AttachThumbURL(fmt.Sprintf("%v%v.png", albionRenderURL, itemID)).
+		Author("Albion Market", "https://www.albion-online-data.com", "").
+		Footer(itemID).
+		Color(ctx.GuildConf().EmbedColor)
+	for _, city := range cities {
+		embed.Field(city, fmt.Sprintf(ctx.Loc("albion_price_line"), sellMin[city], buyMax[city]), true)
+	}
+	embed.Send(ctx)
+}
@@ -0,0 +1,277 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+const (
+	// albionEventsLimit max count of events in one page of events endpoint
+	albionEventsLimit = 51
+	// albionEventsPages max count of pages loaded per update, API does not return events with offset over 1000
+	albionEventsPages = 19
+	// albionWatchInterval interval between checks of latest events
+	albionWatchInterval = 30 * time.Second
+)
+
+// AlbionWatch contains subscription of Discord channel to kills and deaths of Albion guild or alliance
+type AlbionWatch struct {
+	Guild    string
+	Channel  string
+	Language string
+	// Type "guild" or "alliance"
+	Type string
+	ID   string
+	Name string
+	// LastEvent ID of last posted event
+	LastEvent int
+}
+
+// Subject returns Albion guild or alliance ID
+func (w *AlbionWatch) Subject() string {
+	return w.ID
+}
+
+// Target returns Discord channel of killboard
+func (w *AlbionWatch) Target() string {
+	return w.Channel
+}
+
+// AlbionGetEvents returns latest kills of all players, newest first
+func AlbionGetEvents(ctx context.Context, offset int) ([]AlbionKill, error) {
+	var events []AlbionKill
+	err := httpclient.For("albion").GetJSON(ctx, fmt.Sprintf("%v/events?limit=%v&offset=%v", albionAPIURL, albionEventsLimit, offset), &events)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// albionEventSource polls latest events and groups them by involved guilds and alliances
+type albionEventSource struct {
+	// last ID of newest event from previous poll
+	last int
+}
+
+// Poll returns events of watched guilds and alliances, older events first
+func (src *albionEventSource) Poll(ctx context.Context, subjects []string) (map[string][]NotifyItem, error) {
+	var watched = make(map[string]bool)
+	for _, s := range subjects {
+		watched[s] = true
+	}
+	var (
+		items  = make(map[string][]NotifyItem)
+		seen   = make(map[string]map[int]bool)
+		newest = src.last
+	)
+	for page := 0; page < albionEventsPages; page++ {
+		events, err := AlbionGetEvents(ctx, page*albionEventsLimit)
+		if err != nil {
+			if page == 0 {
+				return nil, err
+			}
+			break
+		}
+		oldest := 0
+		for i, e := range events {
+			if e.EventID > newest {
+				newest = e.EventID
+			}
+			if oldest == 0 || e.EventID < oldest {
+				oldest = e.EventID
+			}
+			for _, id := range albionEventSubjects(&events[i]) {
+				if !watched[id] || seen[id][e.EventID] {
+					continue
+				}
+				if seen[id] == nil {
+					seen[id] = make(map[int]bool)
+				}
+				seen[id][e.EventID] = true
+				items[id] = append(items[id], NotifyItem{ID: fmt.Sprintf("%v", e.EventID), Data: &events[i]})
+			}
+		}
+		// First poll only loads latest page, next polls load pages until previous poll is reached
+		if len(events) < albionEventsLimit || src.last == 0 || oldest <= src.last {
+			break
+		}
+	}
+	src.last = newest
+	for id := range items {
+		sort.Slice(items[id], func(i, j int) bool {
+			return items[id][i].Data.(*AlbionKill).EventID < items[id][j].Data.(*AlbionKill).EventID
+		})
+	}
+	return items, nil
+}
+
+// albionEventSubjects returns guilds and alliances of killer, victim and participants
+func albionEventSubjects(kill *AlbionKill) []string {
+	var ids []string
+	players := append([]AlbionPlayer{kill.Killer, kill.Victim}, kill.Participants...)
+	for _, p := range players {
+		if p.GuildId != "" {
+			ids = append(ids, p.GuildId)
+		}
+		if p.AllianceId != "" {
+			ids = append(ids, p.AllianceId)
+		}
+	}
+	return ids
+}
+
+// albionEventDetector skips events posted before
+type albionEventDetector struct {
+	u *AlbionUpdater
+}
+
+// Detect returns events newer than last posted event of watch
+func (d *albionEventDetector) Detect(subject string, subs []NotifySubscription, items []NotifyItem) []NotifyEvent {
+	d.u.Lock()
+	defer d.u.Unlock()
+	var events []NotifyEvent
+	for _, sub := range subs {
+		w := sub.(*AlbionWatch)
+		for _, item := range items {
+			kill := item.Data.(*AlbionKill)
+			if kill.EventID <= w.LastEvent {
+				continue
+			}
+			w.LastEvent = kill.EventID
+			events = append(events, NotifyEvent{Type: NotifyStarted, Subscription: w, Item: item})
+		}
+	}
+	return events
+}
+
+// albionEventRenderer makes kill and death messages
+type albionEventRenderer struct {
+	conf *Config
+}
+
+// Render makes embed of kill, deaths of watched guild or alliance are red
+func (r *albionEventRenderer) Render(ctx context.Context, event NotifyEvent) *NewEmbedStruct {
+	w := event.Subscription.(*AlbionWatch)
+	kill := event.Item.Data.(*AlbionKill)
+	emb := albionKillEmbed(ctx, r.conf, kill, w.Language)
+	if kill.Victim.GuildId == w.ID || kill.Victim.AllianceId == w.ID {
+		emb.Color(13632027)
+		emb.Content = fmt.Sprintf(r.conf.GetLocaleLang("albion_watch_death", w.Language), w.Name)
+	} else {
+		emb.Content = fmt.Sprintf(r.conf.GetLocaleLang("albion_watch_kill", w.Language), w.Name)
+	}
+	return emb
+}
+
+// albionWatchStore keeps watches in memory and MongoDB
+type albionWatchStore struct {
+	u  *AlbionUpdater
+	db *DBWorker
+}
+
+// Subscriptions returns watches of all channels
+func (st *albionWatchStore) Subscriptions() []NotifySubscription {
+	st.u.Lock()
+	defer st.u.Unlock()
+	var subs []NotifySubscription
+	for _, ch := range st.u.Watches {
+		for _, w := range ch {
+			subs = append(subs, w)
+		}
+	}
+	return subs
+}
+
+// Save saves last posted event of watch
+func (st *albionWatchStore) Save(event NotifyEvent, messageID string) {
+	st.db.UpdateAlbionWatch(event.Subscription.(*AlbionWatch))
+}
+
+// AlbionAddWatch posts kills and deaths of guild or alliance in current channel and returns its name,
+// alliance can be found by tag or name of member guild
+func (ctx *Context) AlbionAddWatch(watchType, name string) (string, error) {
+	search, err := AlbionSearchPlayers(ctx.CallContext(), name)
+	if err != nil {
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Searching guild error: %v", err.Error()))
+		return "", errors.New("error searching Albion guild")
+	}
+	var id, title string
+	for _, g := range search.Guilds {
+		if watchType == "guild" && strings.EqualFold(g.Name, name) {
+			id, title = g.ID, g.Name
+			break
+		}
+		if watchType == "alliance" && g.AllianceID != "" &&
+			(strings.EqualFold(g.AllianceName, name) || strings.EqualFold(g.Name, name)) {
+			id, title = g.AllianceID, g.AllianceName
+			break
+		}
+	}
+	if id == "" {
+		return "", errors.New("albion guild not found")
+	}
+
+	ctx.Albion.Lock()
+	_, exists := ctx.Albion.Watches[ctx.TextChannel.ID][id]
+	ctx.Albion.Unlock()
+	if exists {
+		return "", errors.New("already watching")
+	}
+	// Events before watch are not posted
+	events, err := AlbionGetEvents(ctx.CallContext(), 0)
+	if err != nil {
+		ctx.Log("albion", ctx.Guild.ID, fmt.Sprintf("Getting events error: %v", err.Error()))
+		return "", errors.New("error getting Albion events")
+	}
+	watch := &AlbionWatch{
+		Guild:    ctx.Guild.ID,
+		Channel:  ctx.TextChannel.ID,
+		Language: ctx.GuildConf().Language,
+		Type:     watchType,
+		ID:       id,
+		Name:     title,
+	}
+	for _, e := range events {
+		if e.EventID > watch.LastEvent {
+			watch.LastEvent = e.EventID
+		}
+	}
+	ctx.Albion.Lock()
+	// Watch may be added by another command while events were requested
+	if _, exists := ctx.Albion.Watches[watch.Channel][id]; exists {
+		ctx.Albion.Unlock()
+		return "", errors.New("already watching")
+	}
+	if ctx.Albion.Watches[watch.Channel] == nil {
+		ctx.Albion.Watches[watch.Channel] = make(map[string]*AlbionWatch)
+	}
+	ctx.Albion.Watches[watch.Channel][id] = watch
+	ctx.Albion.Unlock()
+	ctx.DB.AddAlbionWatch(watch)
+	return title, nil
+}
+
+// AlbionRemoveWatch stops posting kills of guild or alliance in current channel
+func (ctx *Context) AlbionRemoveWatch(name string) error {
+	ctx.Albion.Lock()
+	var removed *AlbionWatch
+	for id, w := range ctx.Albion.Watches[ctx.TextChannel.ID] {
+		if strings.EqualFold(w.Name, name) || w.ID == name {
+			removed = w
+			delete(ctx.Albion.Watches[ctx.TextChannel.ID], id)
+			break
+		}
+	}
+	ctx.Albion.Unlock()
+	if removed == nil {
+		return errors.New("not watching")
+	}
+	ctx.DB.RemoveAlbionWatch(removed)
+	return nil
+}
@@ -0,0 +1,260 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArgType type of command argument
+type ArgType int
+
+const (
+	// ArgString single word argument
+	ArgString ArgType = iota
+	// ArgText takes all remaining words, must be last argument
+	ArgText
+	// ArgInt integer argument
+	ArgInt
+	// ArgFloat floating point argument
+	ArgFloat
+	// ArgUser user mention or user ID
+	ArgUser
+	// ArgChannel channel mention or channel ID
+	ArgChannel
+	// ArgDuration duration like 1h30m
+	ArgDuration
+)
+
+// locKey returns locale key of argument type name
+func (t ArgType) locKey() string {
+	switch t {
+	case ArgInt:
+		return "arg_type_int"
+	case ArgFloat:
+		return "arg_type_float"
+	case ArgUser:
+		return "arg_type_user"
+	case ArgChannel:
+		return "arg_type_channel"
+	case ArgDuration:
+		return "arg_type_duration"
+	default:
+		return "arg_type_string"
+	}
+}
+
+// Arg describes one argument of command
+type Arg struct {
+	Name     string
+	Type     ArgType
+	Optional bool
+}
+
+// ArgSpec describes arguments of command or subcommand
+type ArgSpec struct {
+	// Command command with subcommands, like "!cron remove"
+	Command string
+	// Description locale key of command description
+	Description string
+	Args        []Arg
+}
+
+// ArgErrorKind kind of argument parsing error
+type ArgErrorKind int
+
+const (
+	// ArgMissing required argument not specified
+	ArgMissing ArgErrorKind = iota
+	// ArgInvalid argument value has wrong format
+	ArgInvalid
+)
+
+// ArgError error of argument parsing
+type ArgError struct {
+	Kind  ArgErrorKind
+	Arg   Arg
+	Value string
+}
+
+func (e *ArgError) Error() string {
+	if e.Kind == ArgMissing {
+		return fmt.Sprintf("missing argument %v", e.Arg.Name)
+	}
+	return fmt.Sprintf("invalid value %q of argument %v", e.Value, e.Arg.Name)
+}
+
+// ParsedArgs contains parsed values of arguments by names
+type ParsedArgs map[string]interface{}
+
+// Has returns true if argument specified
+func (a ParsedArgs) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+// String returns string, text, user or channel argument
+func (a ParsedArgs) String(name string) string {
+	s, _ := a[name].(string)
+	return s
+}
+
+// Int returns integer argument
+func (a ParsedArgs) Int(name string) int {
+	i, _ := a[name].(int)
+	return i
+}
+
+// Float returns floating point argument
+func (a ParsedArgs) Float(name string) float64 {
+	f, _ := a[name].(float64)
+	return f
+}
+
+// Duration returns duration argument
+func (a ParsedArgs) Duration(name string) time.Duration {
+	d, _ := a[name].(time.Duration)
+	return d
+}
+
+// SplitArgs joins words in double quotes into one argument
+func SplitArgs(args []string) []string {
+	var (
+		result []string
+		quoted []string
+	)
+	for _, a := range args {
+		switch {
+		case quoted != nil:
+			if strings.HasSuffix(a, "\"") {
+				result = append(result, strings.Join(append(quoted, strings.TrimSuffix(a, "\"")), " "))
+				quoted = nil
+			} else {
+				quoted = append(quoted, a)
+			}
+		case strings.HasPrefix(a, "\"") && len(a) > 1 && strings.HasSuffix(a, "\""):
+			result = append(result, a[1:len(a)-1])
+		case strings.HasPrefix(a, "\""):
+			quoted = []string{a[1:]}
+		case a != "":
+			result = append(result, a)
+		}
+	}
+	// unclosed quote takes all remaining words
+	if quoted != nil {
+		result = append(result, strings.Join(quoted, " "))
+	}
+	return result
+}
+
+// mentionID returns ID from mention like <@!123>, <#123> or plain ID
+func mentionID(value string, prefixes ...string) (string, bool) {
+	if strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">") {
+		value = value[1 : len(value)-1]
+		for _, p := range prefixes {
+			if strings.HasPrefix(value, p) {
+				value = strings.TrimPrefix(value, p)
+				break
+			}
+		}
+	}
+	if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// Parse parses arguments by spec
+func (s ArgSpec) Parse(args []string) (ParsedArgs, error) {
+	var (
+		parsed = make(ParsedArgs)
+		words  = SplitArgs(args)
+	)
+	for i, arg := range s.Args {
+		if i >= len(words) {
+			if arg.Optional {
+				continue
+			}
+			return parsed, &ArgError{Kind: ArgMissing, Arg: arg}
+		}
+		var (
+			value = words[i]
+			ok    = true
+		)
+		switch arg.Type {
+		case ArgString:
+			parsed[arg.Name] = value
+		case ArgText:
+			parsed[arg.Name] = strings.Join(words[i:], " ")
+		case ArgInt:
+			v, err := strconv.Atoi(value)
+			parsed[arg.Name], ok = v, err == nil
+		case ArgFloat:
+			v, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+			parsed[arg.Name], ok = v, err == nil
+		case ArgUser:
+			parsed[arg.Name], ok = mentionID(value, "@!", "@")
+		case ArgChannel:
+			parsed[arg.Name], ok = mentionID(value, "#")
+		case ArgDuration:
+			v, err := time.ParseDuration(value)
+			parsed[arg.Name], ok = v, err == nil
+		}
+		if !ok {
+			delete(parsed, arg.Name)
+			return parsed, &ArgError{Kind: ArgInvalid, Arg: arg, Value: value}
+		}
+	}
+	return parsed, nil
+}
+
+// Usage returns usage string of command like "`!cron remove [id:number]`"
+func (ctx *Context) Usage(s ArgSpec) string {
+	usage := s.Command
+	for _, a := range s.Args {
+		name := a.Name
+		if a.Type != ArgString && a.Type != ArgText {
+			name += ":" + ctx.Loc(a.Type.locKey())
+		}
+		if a.Optional {
+			usage += " (" + name + ")"
+		} else {
+			usage += " [" + name + "]"
+		}
+	}
+	return "`" + usage + "`"
+}
+
+// Help returns help lines generated from specs
+func (ctx *Context) Help(specs []ArgSpec) string {
+	var lines []string
+	for _, s := range specs {
+		lines = append(lines, fmt.Sprintf("%v | %v", ctx.Usage(s), ctx.Loc(s.Description)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseArgs parses command arguments starting from offset by spec,
+// replies with localized usage message and returns false on error
+func (ctx *Context) ParseArgs(s ArgSpec, offset int) (ParsedArgs, bool) {
+	var args []string
+	if len(ctx.Args) > offset {
+		args = ctx.Args[offset:]
+	}
+	parsed, err := s.Parse(args)
+	if err == nil {
+		return parsed, true
+	}
+	var reason string
+	if e, ok := err.(*ArgError); ok {
+		switch e.Kind {
+		case ArgMissing:
+			reason = fmt.Sprintf(ctx.Loc("arg_missing"), e.Arg.Name)
+		case ArgInvalid:
+			reason = fmt.Sprintf(ctx.Loc("arg_invalid"), e.Value, e.Arg.Name, ctx.Loc(e.Arg.Type.locKey()))
+		}
+	}
+	ctx.ReplyEmbed(ctx.Loc("arg_error"), fmt.Sprintf("%v\n%v %v", reason, ctx.Loc("arg_usage"), ctx.Usage(s)))
+	return parsed, false
+}
@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"empty", nil, nil},
+		{"plain", []string{"a", "b"}, []string{"a", "b"}},
+		{"quoted words", []string{`"hello`, `world"`, "x"}, []string{"hello world", "x"}},
+		{"quoted word", []string{`"one"`, "two"}, []string{"one", "two"}},
+		{"quoted three words", []string{`"a`, "b", `c"`}, []string{"a b c"}},
+		{"unclosed quote", []string{"x", `"a`, "b"}, []string{"x", "a b"}},
+		{"empty words", []string{"a", "", "b"}, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitArgs(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgSpecParse(t *testing.T) {
+	spec := ArgSpec{Command: "!test", Args: []Arg{
+		{Name: "word", Type: ArgString},
+		{Name: "count", Type: ArgInt},
+		{Name: "value", Type: ArgFloat, Optional: true},
+		{Name: "text", Type: ArgText, Optional: true},
+	}}
+	tests := []struct {
+		name    string
+		args    string
+		want    ParsedArgs
+		errKind ArgErrorKind
+		errArg  string
+		errVal  string
+	}{
+		{
+			name: "all arguments",
+			args: "w 3 1.5 some trailing text",
+			want: ParsedArgs{"word": "w", "count": 3, "value": 1.5, "text": "some trailing text"},
+		},
+		{
+			name: "float with comma",
+			args: "w 3 1,5",
+			want: ParsedArgs{"word": "w", "count": 3, "value": 1.5},
+		},
+		{
+			name: "optional arguments missing",
+			args: "w 3",
+			want: ParsedArgs{"word": "w", "count": 3},
+		},
+		{
+			name: "quoted string",
+			args: `"two words" 3 0 "quoted text" tail`,
+			want: ParsedArgs{"word": "two words", "count": 3, "value": 0.0, "text": "quoted text tail"},
+		},
+		{
+			name:    "required argument missing",
+			args:    "w",
+			want:    ParsedArgs{"word": "w"},
+			errKind: ArgMissing,
+			errArg:  "count",
+		},
+		{
+			name:    "invalid int",
+			args:    "w three",
+			want:    ParsedArgs{"word": "w"},
+			errKind: ArgInvalid,
+			errArg:  "count",
+			errVal:  "three",
+		},
+		{
+			name:    "invalid float",
+			args:    "w 3 x",
+			want:    ParsedArgs{"word": "w", "count": 3},
+			errKind: ArgInvalid,
+			errArg:  "value",
+			errVal:  "x",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := spec.Parse(strings.Fields(tt.args))
+			if tt.errArg == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.errArg != "" {
+				e, ok := err.(*ArgError)
+				if !ok {
+					t.Fatalf("error = %v, want *ArgError", err)
+				}
+				if e.Kind != tt.errKind || e.Arg.Name != tt.errArg || e.Value != tt.errVal {
+					t.Errorf("error = %+v, want kind %v of %v with value %q", e, tt.errKind, tt.errArg, tt.errVal)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgSpecParseTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   ArgType
+		value string
+		want  interface{}
+		ok    bool
+	}{
+		{"user mention", ArgUser, "<@123>", "123", true},
+		{"user nickname mention", ArgUser, "<@!123>", "123", true},
+		{"user ID", ArgUser, "123", "123", true},
+		{"user name", ArgUser, "someone", nil, false},
+		{"role mention as user", ArgUser, "<@&123>", nil, false},
+		{"channel mention", ArgChannel, "<#456>", "456", true},
+		{"channel ID", ArgChannel, "456", "456", true},
+		{"channel name", ArgChannel, "#general", nil, false},
+		{"duration", ArgDuration, "1h30m", 90 * time.Minute, true},
+		{"wrong duration", ArgDuration, "soon", nil, false},
+		{"negative int", ArgInt, "-2", -2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := ArgSpec{Args: []Arg{{Name: "arg", Type: tt.typ}}}
+			got, err := spec.Parse([]string{tt.value})
+			if (err == nil) != tt.ok {
+				t.Fatalf("error = %v, want ok %v", err, tt.ok)
+			}
+			if tt.ok && !reflect.DeepEqual(got["arg"], tt.want) {
+				t.Errorf("parsed = %#v, want %#v", got["arg"], tt.want)
+			}
+			if !tt.ok && got.Has("arg") {
+				t.Errorf("invalid value %q is kept in parsed arguments", tt.value)
+			}
+		})
+	}
+}
+
+func testArgsContext(args ...string) *Context {
+	return &Context{
+		Conf: &Config{Locales: LocalesMap{"en": {
+			"arg_type_int":   "number",
+			"help_test":      "Test command",
+			"help_test_list": "Test list",
+		}}},
+		Guilds: &GuildsMap{guilds: map[string]*GuildData{"1": {ID: "1", Language: "en"}}},
+		Guild:  &discordgo.Guild{ID: "1"},
+		Args:   args,
+	}
+}
+
+func TestUsageAndHelp(t *testing.T) {
+	ctx := testArgsContext()
+	spec := ArgSpec{Command: "!test add", Description: "help_test", Args: []Arg{
+		{Name: "id", Type: ArgInt},
+		{Name: "name", Type: ArgString},
+		{Name: "text", Type: ArgText, Optional: true},
+	}}
+	if got, want := ctx.Usage(spec), "`!test add [id:number] [name] (text)`"; got != want {
+		t.Errorf("Usage = %v, want %v", got, want)
+	}
+	list := ArgSpec{Command: "!test list", Description: "help_test_list"}
+	want := "`!test add [id:number] [name] (text)` | Test command\n`!test list` | Test list"
+	if got := ctx.Help([]ArgSpec{spec, list}); got != want {
+		t.Errorf("Help = %q, want %q", got, want)
+	}
+}
+
+func TestParseArgsOffset(t *testing.T) {
+	ctx := testArgsContext("remove", "7", "extra")
+	spec := ArgSpec{Command: "!test remove", Args: []Arg{{Name: "id", Type: ArgInt}, {Name: "rest", Type: ArgText, Optional: true}}}
+	args, ok := ctx.ParseArgs(spec, 1)
+	if !ok {
+		t.Fatal("ParseArgs failed")
+	}
+	if args.Int("id") != 7 || args.String("rest") != "extra" {
+		t.Errorf("parsed = %v", args)
+	}
+	if args, ok := ctx.ParseArgs(ArgSpec{Args: []Arg{{Name: "opt", Optional: true}}}, 5); !ok || args.Has("opt") {
+		t.Errorf("offset beyond arguments: parsed = %v, ok = %v", args, ok)
+	}
+}
@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"errors"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// AutoTranslateChannel contains rule of automatic translation from one channel to another
+type AutoTranslateChannel struct {
+	Source   string
+	Target   string
+	Language string
+}
+
+// GetAutoTranslate returns automatic translation rules for channel
+func (g *GuildData) GetAutoTranslate(channelID string) []AutoTranslateChannel {
+	var rules []AutoTranslateChannel
+	for _, r := range g.AutoTranslate {
+		if r.Source == channelID {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// AddAutoTranslate adds automatic translation rule to guild
+func (ctx *Context) AddAutoTranslate(source, target, language string) error {
+	return ctx.updateGuild(func(guild *GuildData) error {
+		for _, r := range guild.AutoTranslate {
+			if r.Source == source && r.Target == target {
+				return errors.New("rule already exists")
+			}
+		}
+		guild.AutoTranslate = append(guild.AutoTranslate, AutoTranslateChannel{Source: source, Target: target, Language: language})
+		return ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"autotranslate": guild.AutoTranslate}})
+	})
+}
+
+// SetReactionTranslate enables or disables translation by flag reactions in guild
+func (ctx *Context) SetReactionTranslate(enabled bool) error {
+	return ctx.updateGuild(func(guild *GuildData) error {
+		guild.ReactionTranslate = enabled
+		return ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"reactiontranslate": enabled}})
+	})
+}
+
+// RemoveAutoTranslate removes automatic translation rule from guild
+func (ctx *Context) RemoveAutoTranslate(source, target string) error {
+	return ctx.updateGuild(func(guild *GuildData) error {
+		var rules []AutoTranslateChannel
+		for _, r := range guild.AutoTranslate {
+			if r.Source != source || r.Target != target {
+				rules = append(rules, r)
+			}
+		}
+		if len(rules) == len(guild.AutoTranslate) {
+			return errors.New("rule not found")
+		}
+		guild.AutoTranslate = rules
+		return ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"autotranslate": guild.AutoTranslate}})
+	})
+}
@@ -1,13 +1,18 @@
 package bot
 
+import "sync"
+
 // BlackList contains ignored guilds and users
 type BlackListStruct struct {
+	sync.RWMutex
 	Guilds []string
 	Users []string
 }
 
 // CheckGuild returns true if guild in blacklist
-func (b BlackListStruct) CheckGuild(id string) bool {
+func (b *BlackListStruct) CheckGuild(id string) bool {
+	b.RLock()
+	defer b.RUnlock()
 	for _,g := range b.Guilds {
 		if g == id {
 			return true
@@ -17,7 +22,9 @@ func (b BlackListStruct) CheckGuild(id string) bool {
 }
 
 // CheckUser returns true if user in blacklist
-func (b BlackListStruct) CheckUser(id string) bool {
+func (b *BlackListStruct) CheckUser(id string) bool {
+	b.RLock()
+	defer b.RUnlock()
 	for _,g := range b.Users {
 		if g == id {
 			return true
@@ -26,38 +33,79 @@ func (b BlackListStruct) CheckUser(id string) bool {
 	return false
 }
 
-// BlacklistAddGuild adds guild in blacklist
-func (ctx *Context) BlacklistAddGuild(id string) {
-	ctx.BlackList.Guilds = append(ctx.BlackList.Guilds, id)
-	ctx.DB.AddBlacklistGuild(id)
+// List returns copies of blacklisted guilds and users
+func (b *BlackListStruct) List() (guilds, users []string) {
+	b.RLock()
+	defer b.RUnlock()
+	return append([]string{}, b.Guilds...), append([]string{}, b.Users...)
 }
 
-// BlacklistAddUser adds user in blacklist
-func (ctx *Context) BlacklistAddUser(id string) {
-	ctx.BlackList.Users = append(ctx.BlackList.Users, id)
-	ctx.DB.AddBlacklistUser(id)
+// AddGuild adds guild in blacklist and database if it is not in blacklist yet
+func (b *BlackListStruct) AddGuild(db *DBWorker, id string) {
+	b.Lock()
+	defer b.Unlock()
+	if containsString(b.Guilds, id) {
+		return
+	}
+	b.Guilds = append(b.Guilds, id)
+	db.AddBlacklistGuild(id)
 }
 
-// BlacklistRemoveGuild removes guild from blacklist
-func (ctx *Context) BlacklistRemoveGuild(id string) {
+// AddUser adds user in blacklist and database if it is not in blacklist yet
+func (b *BlackListStruct) AddUser(db *DBWorker, id string) {
+	b.Lock()
+	defer b.Unlock()
+	if containsString(b.Users, id) {
+		return
+	}
+	b.Users = append(b.Users, id)
+	db.AddBlacklistUser(id)
+}
+
+// RemoveGuild removes guild from blacklist and database
+func (b *BlackListStruct) RemoveGuild(db *DBWorker, id string) {
+	b.Lock()
+	defer b.Unlock()
 	var newArray []string
-	for _, g := range ctx.BlackList.Guilds {
+	for _, g := range b.Guilds {
 		if g != id {
 			newArray = append(newArray, g)
 		}
 	}
-	ctx.BlackList.Guilds = newArray
-	ctx.DB.RemoveBlacklistGuild(id)
+	b.Guilds = newArray
+	db.RemoveBlacklistGuild(id)
 }
 
-// BlacklistRemoveUser removes user from blacklist
-func (ctx *Context) BlacklistRemoveUser(id string) {
+// RemoveUser removes user from blacklist and database
+func (b *BlackListStruct) RemoveUser(db *DBWorker, id string) {
+	b.Lock()
+	defer b.Unlock()
 	var newArray []string
-	for _, u := range ctx.BlackList.Users {
+	for _, u := range b.Users {
 		if u != id {
 			newArray = append(newArray, u)
 		}
 	}
-	ctx.BlackList.Users = newArray
-	ctx.DB.RemoveBlacklistUser(id)
+	b.Users = newArray
+	db.RemoveBlacklistUser(id)
+}
+
+// BlacklistAddGuild adds guild in blacklist
+func (ctx *Context) BlacklistAddGuild(id string) {
+	ctx.BlackList.AddGuild(ctx.DB, id)
+}
+
+// BlacklistAddUser adds user in blacklist
+func (ctx *Context) BlacklistAddUser(id string) {
+	ctx.BlackList.AddUser(ctx.DB, id)
+}
+
+// BlacklistRemoveGuild removes guild from blacklist
+func (ctx *Context) BlacklistRemoveGuild(id string) {
+	ctx.BlackList.RemoveGuild(ctx.DB, id)
+}
+
+// BlacklistRemoveUser removes user from blacklist
+func (ctx *Context) BlacklistRemoveUser(id string) {
+	ctx.BlackList.RemoveUser(ctx.DB, id)
 }
\ No newline at end of file
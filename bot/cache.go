@@ -0,0 +1,22 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/cache"
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+// InitCache creates cache of external API responses and sets it to http client
+func InitCache(conf *Config, db *DBWorker) {
+	var backend cache.Backend
+	if conf.Cache.Persist {
+		backend = cache.NewMongoBackend(db.DBSession.DB(db.DBName).C("cache"))
+	}
+	c := cache.New(conf.Cache.Size, backend)
+	var ttls = make(map[string]time.Duration)
+	for name, t := range conf.Cache.TTL {
+		ttls[name] = time.Duration(t) * time.Second
+	}
+	httpclient.ConfigureCache(c, ttls)
+}
@@ -1,5 +1,7 @@
 package bot
 
+import "sort"
+
 type (
 	// Command : Executable command function
 	Command func(Context)
@@ -9,13 +11,45 @@ type (
 
 	// CommandHandler : Command handler struct
 	CommandHandler struct {
-		cmds CmdMap
+		cmds  CmdMap
+		infos map[string]*CommandInfo
+	}
+
+	// Permission : Level of permissions required by command
+	Permission int
+
+	// CommandInfo : Command metadata used for help
+	CommandInfo struct {
+		Name string
+		// Category locale key of command category
+		Category string
+		// Description locale key of short description
+		Description string
+		// Usage locale key of detailed usage
+		Usage string
+		// AdminUsage locale key of usage shown only to bot admins
+		AdminUsage string
+		// Specs arguments of subcommands, usage is generated from them
+		Specs []ArgSpec
+		// AdminSpecs arguments of subcommands shown only to bot admins
+		AdminSpecs []ArgSpec
+		Examples   []string
+		Permission Permission
 	}
 )
 
+const (
+	// PermissionUser command available to all users
+	PermissionUser Permission = iota
+	// PermissionServerAdmin command available to server admins
+	PermissionServerAdmin
+	// PermissionBotAdmin command available to bot admins
+	PermissionBotAdmin
+)
+
 // NewCommandHandler creates command handler
 func NewCommandHandler() *CommandHandler {
-	return &CommandHandler{make(CmdMap)}
+	return &CommandHandler{make(CmdMap), make(map[string]*CommandInfo)}
 }
 
 // GetCmds returns handler commands
@@ -29,12 +63,32 @@ func (handler CommandHandler) Get(name string) (*Command, bool) {
 	return &cmd, found
 }
 
-// Register adds new command in handler
-func (handler CommandHandler) Register(name string, command Command) {
+// Register adds new command with metadata in handler
+func (handler CommandHandler) Register(name string, command Command, info CommandInfo) {
 	handler.cmds[name] = command
 	if len(name) > 1 {
 		handler.cmds[name[:1]] = command
 	}
+	info.Name = name
+	handler.infos[name] = &info
+}
+
+// Info returns metadata of command by command name
+func (handler CommandHandler) Info(name string) (*CommandInfo, bool) {
+	info, found := handler.infos[name]
+	return info, found
+}
+
+// Infos returns metadata of all commands sorted by name
+func (handler CommandHandler) Infos() []*CommandInfo {
+	var infos []*CommandInfo
+	for _, info := range handler.infos {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
 }
 
 
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 )
 
 // WeatherConfig Weather config struct
@@ -32,6 +35,12 @@ type GeneralConfig struct {
 	DatabaseName     string
 	GeocodingApiKey  string
 	AdminID          string
+	// PagesTimeout seconds while pages of list can be switched by reactions
+	PagesTimeout int
+	// PromptTimeout seconds of waiting for user answer on confirmations and prompts
+	PromptTimeout int
+	// ErrorsChannel ID of channel where errors of commands are reported, optional
+	ErrorsChannel string
 }
 
 // NewsConfig News config struct
@@ -39,6 +48,12 @@ type NewsConfig struct {
 	APIKey   string
 	Country  string
 	Articles int
+	// Language default language of news search
+	Language string
+	// FeedInterval minutes between RSS feeds updates
+	FeedInterval int
+	// FeedsLimit maximum count of feed subscriptions per guild
+	FeedsLimit int
 }
 
 // MetricsConfig InfluxDB connection settings
@@ -57,17 +72,106 @@ type DBLConfig struct {
 
 // TwitchConfig contains twitch api configs
 type TwitchConfig struct {
-	ClientID string
+	ClientID     string
+	ClientSecret string
+	// EventSub webhook settings, streams are polled if callback is not set
+	EventSub TwitchEventSubConfig
 }
 
-// TranslateConfig Yandex translate config struct
-type TranslateConfig struct {
+// TwitchEventSubConfig contains settings of Twitch EventSub webhook server
+type TwitchEventSubConfig struct {
+	// Listen address of webhook server
+	Listen string
+	// CallbackURL public HTTPS address of webhook, Twitch sends notifications here
+	CallbackURL string
+	// Secret signs notifications, from 10 to 100 characters
+	Secret string
+}
+
+// YoutubeNotifyConfig contains settings of YouTube channels announcer
+type YoutubeNotifyConfig struct {
+	// APIKey YouTube Data API key, used to detect live streams, optional
 	APIKey string
 }
 
+// AlbionConfig contains Albion Online killboard settings
+type AlbionConfig struct {
+	// IconsDir directory of cached item icons
+	IconsDir string
+	// MarketURL Albion Data Project server of game region
+	MarketURL string
+	// WatchHours hours of sending new kills of watched player, 24 by default
+	WatchHours int
+}
+
+// DashboardConfig contains settings of web dashboard
+type DashboardConfig struct {
+	// Listen address of dashboard server, dashboard is disabled if empty
+	Listen string
+	// URL public address of dashboard, used in OAuth2 redirects
+	URL string
+	// ClientID and ClientSecret of Discord application
+	ClientID     string
+	ClientSecret string
+}
+
+// AdminAPIConfig contains settings of bot owner HTTP API
+type AdminAPIConfig struct {
+	// Listen address of API server, should be local, API is disabled if empty
+	Listen string
+	// Token is sent by clients in "Authorization: Bearer" header
+	Token string
+}
+
+// GeocodingConfig contains geocoding providers settings
+type GeocodingConfig struct {
+	// Providers order of geocoding providers (geonames, yandex, nominatim)
+	Providers []string
+	// UserAgent is sent to Nominatim
+	UserAgent string
+}
+
+// HTTPConfig contains settings of requests to external APIs
+type HTTPConfig struct {
+	UserAgent string
+	// Timeout default timeout of requests in seconds
+	Timeout int
+	// CommandTimeout max time of external requests made by one command in seconds
+	CommandTimeout int
+	// Timeouts timeouts of requests by provider name in seconds
+	Timeouts map[string]int
+}
+
+// CacheConfig contains settings of external API responses cache
+type CacheConfig struct {
+	// Size max count of responses in memory
+	Size int
+	// Persist stores responses in database to keep them between restarts
+	Persist bool
+	// TTL lifetime of responses by provider name in seconds
+	TTL map[string]int
+}
+
+// TranslateConfig translate config struct
+type TranslateConfig struct {
+	// Provider translation backend: yandex or libretranslate
+	Provider string
+	APIKey   string
+	// FolderID Yandex Cloud folder
+	FolderID string
+	// URL LibreTranslate instance address
+	URL string
+}
+
 // CurrencyConfig Currency config struct
 type CurrencyConfig struct {
 	Default []string
+	// Providers order of rate providers (cbr, ecb, crypto)
+	Providers []string
+	// AlertCooldown minimal minutes between repeated alerts
+	AlertCooldown int
+	// AlertsLimit maximum count of alerts per user
+	AlertsLimit int
 }
 
 // LocalesMap Map with locales
@@ -83,18 +187,25 @@ type DarkSkyConfig struct {
 
 // Config Main config struct. Contains all another config structs data.
 type Config struct {
-	Weather      WeatherConfig
-	General      GeneralConfig
-	News         NewsConfig
-	Translate    TranslateConfig
-	Locales      LocalesMap
-	Currency     CurrencyConfig
-	WeatherCodes WeatherCodesMap
-	Metrics      MetricsConfig
-	DBL          DBLConfig
-	Twitch       TwitchConfig
-	DarkSky      DarkSkyConfig
-	Voice        VoiceConfig
+	Weather       WeatherConfig
+	General       GeneralConfig
+	News          NewsConfig
+	Translate     TranslateConfig
+	Locales       LocalesMap
+	Currency      CurrencyConfig
+	WeatherCodes  WeatherCodesMap
+	Metrics       MetricsConfig
+	DBL           DBLConfig
+	Twitch        TwitchConfig
+	YoutubeNotify YoutubeNotifyConfig
+	Albion        AlbionConfig
+	Dashboard     DashboardConfig
+	AdminAPI      AdminAPIConfig
+	DarkSky       DarkSkyConfig
+	Voice         VoiceConfig
+	Geocoding     GeocodingConfig
+	HTTP          HTTPConfig
+	Cache         CacheConfig
 }
 
 // GetLocale returns locale string by key
@@ -119,9 +230,19 @@ func LoadConfig() *Config {
 	}
 	cfg.LoadLocales()
 	cfg.LoadWeatherCodes()
+	cfg.configureHTTP()
 	return &cfg
 }
 
+// configureHTTP applies settings of external requests to http client
+func (c *Config) configureHTTP() {
+	var timeouts = make(map[string]time.Duration)
+	for name, t := range c.HTTP.Timeouts {
+		timeouts[name] = time.Duration(t) * time.Second
+	}
+	httpclient.Configure(c.HTTP.UserAgent, time.Duration(c.HTTP.Timeout)*time.Second, timeouts)
+}
+
 // LoadLocales loads locales from file 'locales.json'. Terminate program if error.
 func (c *Config) LoadLocales() {
 	file, e := ioutil.ReadFile("./locales.json")
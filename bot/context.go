@@ -1,7 +1,10 @@
 package bot
 
 import (
+	"context"
 	"fmt"
+
+	"github.com/FlameInTheDark/dtbot/api/geocoding"
 	"github.com/bwmarrin/discordgo"
 	"gopkg.in/robfig/cron.v2"
 )
@@ -29,15 +32,21 @@ type Context struct {
 	Data       *DataType
 	Guilds     *GuildsMap
 	Twitch     *Twitch
+	YTNotify   *YoutubeNotify
 	Albion     *AlbionUpdater
 	BlackList  *BlackListStruct
+	Geocoder   *geocoding.Service
+
+	// callCtx carries deadline of external requests made by command
+	callCtx context.Context
 }
 
 // NewContext create new context
 func NewContext(botID string, discord *discordgo.Session, guild *discordgo.Guild, textChannel *discordgo.Channel,
 	user *discordgo.User, message *discordgo.MessageCreate, conf *Config, cmdHandler *CommandHandler,
 	sessions *SessionManager, youtube *Youtube, botMsg *BotMessages, dataType *DataType, dbWorker *DBWorker,
-	guilds *GuildsMap, botCron *cron.Cron, twitch *Twitch, albion *AlbionUpdater, blacklist *BlackListStruct) *Context {
+	guilds *GuildsMap, botCron *cron.Cron, twitch *Twitch, ytNotify *YoutubeNotify, albion *AlbionUpdater,
+	blacklist *BlackListStruct, geocoder *geocoding.Service) *Context {
 	ctx := new(Context)
 	ctx.BotID = botID
 	ctx.Discord = discord
@@ -55,8 +64,10 @@ func NewContext(botID string, discord *discordgo.Session, guild *discordgo.Guild
 	ctx.Guilds = guilds
 	ctx.Cron = botCron
 	ctx.Twitch = twitch
+	ctx.YTNotify = ytNotify
 	ctx.Albion = albion
 	ctx.BlackList = blacklist
+	ctx.Geocoder = geocoder
 	return ctx
 }
 
@@ -86,7 +97,8 @@ func (ctx *Context) WeatherCode(code string) string {
 
 // GuildConf returns config of guild
 func (ctx *Context) GuildConf() *GuildData {
-	return ctx.Guilds.Guilds[ctx.Guild.ID]
+	guild, _ := ctx.Guilds.Get(ctx.Guild.ID)
+	return guild
 }
 
 // GetVoiceChannel returns user voice channel
@@ -121,20 +133,30 @@ func (ctx *Context) GetVoiceChannel() *discordgo.Channel {
 
 // GetGuild return data about current guild
 func (ctx *Context) GetGuild() *GuildData {
-	if _, ok := ctx.Guilds.Guilds[ctx.Guild.ID]; !ok {
-		newData := &GuildData{
-			ID:          ctx.Guild.ID,
-			WeatherCity: ctx.Conf.Weather.City,
-			NewsCounty:  ctx.Conf.News.Country,
-			Language:    ctx.Conf.General.Language,
-			Timezone:    ctx.Conf.General.Timezone,
-			EmbedColor:  ctx.Conf.General.EmbedColor,
-		}
-		_ = ctx.DB.DBSession.DB(ctx.DB.DBName).C("guilds").Insert(newData)
-		ctx.Guilds.Guilds[ctx.Guild.ID] = newData
-		return ctx.Guilds.Guilds[ctx.Guild.ID]
+	if guild, ok := ctx.Guilds.Get(ctx.Guild.ID); ok {
+		return guild
+	}
+	newData := &GuildData{
+		ID:           ctx.Guild.ID,
+		WeatherCity:  ctx.Conf.Weather.City,
+		NewsCounty:   ctx.Conf.News.Country,
+		NewsLanguage: ctx.Conf.News.Language,
+		Language:     ctx.Conf.General.Language,
+		Timezone:     ctx.Conf.General.Timezone,
+		EmbedColor:   ctx.Conf.General.EmbedColor,
+	}
+	// Settings may be added by another message while this one was handled
+	if guild := ctx.Guilds.add(newData); guild != newData {
+		return guild
 	}
-	return ctx.Guilds.Guilds[ctx.Guild.ID]
+	_ = ctx.DB.DBSession.DB(ctx.DB.DBName).C("guilds").Insert(newData)
+	return newData
+}
+
+// updateGuild changes settings of current guild, see GuildsMap.Update
+func (ctx *Context) updateGuild(change func(guild *GuildData) error) error {
+	ctx.GetGuild()
+	return ctx.Guilds.Update(ctx.Guild.ID, change)
 }
 
 // Log saves log in database
@@ -150,3 +172,11 @@ func (ctx *Context) Arg(index int) string {
 	}
 	return ""
 }
+
+// CallContext returns context of command execution that should be passed to external requests
+func (ctx *Context) CallContext() context.Context {
+	if ctx.callCtx == nil {
+		return context.Background()
+	}
+	return ctx.callCtx
+}
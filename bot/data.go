@@ -2,11 +2,15 @@ package bot
 
 import (
 	"errors"
+	"sync"
+
 	"gopkg.in/robfig/cron.v2"
 )
 
 // DataType contains some data
 type DataType struct {
+	// Mutex guards guild schedules, they are also edited from dashboard
+	sync.Mutex
 	Polls          map[string]*PollType
 	GuildSchedules map[string]*GuildSchedule
 }
@@ -75,42 +79,60 @@ func (data *DataType) EndPoll(ctx *Context) (results map[string]int, err error)
 
 // AddCronJob adds new cron job to guild
 func (data *DataType) AddCronJob(ctx *Context, id cron.EntryID, cmd string) error {
+	data.Lock()
+	defer data.Unlock()
 	if _, ok := data.GuildSchedules[ctx.Guild.ID]; !ok {
 		data.GuildSchedules[ctx.Guild.ID] = &GuildSchedule{CronJobs: make(map[cron.EntryID]string)}
-		data.GuildSchedules[ctx.Guild.ID].CronJobs[id] = cmd
-		return nil
 	}
-	return errors.New("Error adding cron job")
+	if _, ok := data.GuildSchedules[ctx.Guild.ID].CronJobs[id]; ok {
+		return errors.New("Error adding cron job")
+	}
+	data.GuildSchedules[ctx.Guild.ID].CronJobs[id] = cmd
+	return nil
 }
 
 // CronIsFull checks if cron jobs is maximum count
 func (data *DataType) CronIsFull(ctx *Context) bool {
-	if _, ok := data.GuildSchedules[ctx.Guild.ID]; ok {
-		if len(data.GuildSchedules[ctx.Guild.ID].CronJobs) >= 10 {
-			return true
-		}
-	}
-	return false
+	return len(data.GuildCronJobs(ctx.Guild.ID)) >= 10
 }
 
 // CronRemove removes job from guild
 func (data *DataType) CronRemove(ctx *Context, id cron.EntryID) error {
-	if _, ok := data.GuildSchedules[ctx.Guild.ID]; ok {
-		if _, ok := data.GuildSchedules[ctx.Guild.ID].CronJobs[id]; ok {
-			ctx.Cron.Remove(id)
-			delete(data.GuildSchedules[ctx.Guild.ID].CronJobs, id)
-			return nil
-		}
-	}
-	return errors.New("Job not found")
+	return data.RemoveGuildCronJob(ctx.Cron, ctx.Guild.ID, id)
 }
 
 // CronList shows cron jobs
 func (data *DataType) CronList(ctx *Context) (*GuildSchedule, error) {
-	if _, ok := data.GuildSchedules[ctx.Guild.ID]; ok {
-		if len(data.GuildSchedules[ctx.Guild.ID].CronJobs) > 0 {
-			return data.GuildSchedules[ctx.Guild.ID], nil
+	jobs := data.GuildCronJobs(ctx.Guild.ID)
+	if len(jobs) == 0 {
+		return nil, errors.New("Schedule is empty")
+	}
+	return &GuildSchedule{CronJobs: jobs}, nil
+}
+
+// GuildCronJobs returns copy of guild cron jobs
+func (data *DataType) GuildCronJobs(guildID string) map[cron.EntryID]string {
+	data.Lock()
+	defer data.Unlock()
+	var jobs = make(map[cron.EntryID]string)
+	if s, ok := data.GuildSchedules[guildID]; ok {
+		for id, cmd := range s.CronJobs {
+			jobs[id] = cmd
 		}
 	}
-	return nil, errors.New("Schedule is empty")
+	return jobs
+}
+
+// RemoveGuildCronJob stops cron job and removes it from guild
+func (data *DataType) RemoveGuildCronJob(c *cron.Cron, guildID string, id cron.EntryID) error {
+	data.Lock()
+	defer data.Unlock()
+	if s, ok := data.GuildSchedules[guildID]; ok {
+		if _, ok := s.CronJobs[id]; ok {
+			c.Remove(id)
+			delete(s.CronJobs, id)
+			return nil
+		}
+	}
+	return errors.New("Job not found")
 }
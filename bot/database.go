@@ -6,6 +6,7 @@ import (
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -27,16 +28,29 @@ type GuildData struct {
 	ID          string
 	WeatherCity string
 	NewsCounty  string
-	Language    string
-	Timezone    int
-	EmbedColor  int
-	VoiceVolume float32
-	Greeting    string
+	// NewsLanguage language of news search results
+	NewsLanguage string
+	// NewsSources allowed news sources, all sources allowed if empty
+	NewsSources []string
+	// NewsExcluded denied news sources
+	NewsExcluded []string
+	Language     string
+	Timezone     int
+	EmbedColor   int
+	VoiceVolume  float32
+	Greeting     string
+	// AutoTranslate rules of automatic translation between channels
+	AutoTranslate []AutoTranslateChannel
+	// ReactionTranslate enables translation by country flag reactions
+	ReactionTranslate bool
 }
 
 // GuildsMap contains guilds settings
 type GuildsMap struct {
-	Guilds map[string]*GuildData
+	mu sync.RWMutex
+	// update makes changes of settings one by one
+	update sync.Mutex
+	guilds map[string]*GuildData
 }
 
 // RadioStation contains info about radio station
@@ -47,6 +61,52 @@ type RadioStation struct {
 	Category string
 }
 
+// CurrencyAlert contains user threshold of currency rate
+type CurrencyAlert struct {
+	UserID   string
+	Currency string
+	Target   string
+	// Operator is ">" or "<"
+	Operator string
+	Value    float64
+	Language string
+	// Triggered is true while rate is beyond threshold
+	Triggered bool
+	LastFired int64
+}
+
+// Check returns true if rate is beyond threshold
+func (a *CurrencyAlert) Check(rate float64) bool {
+	if a.Operator == "<" {
+		return rate < a.Value
+	}
+	return rate > a.Value
+}
+
+// NewsFeed contains RSS or Atom feed subscription of channel
+type NewsFeed struct {
+	Guild   string
+	Channel string
+	URL     string
+	Title   string
+}
+
+// Subject returns URL of feed
+func (f NewsFeed) Subject() string {
+	return f.URL
+}
+
+// Target returns Discord channel of feed items
+func (f NewsFeed) Target() string {
+	return f.Channel
+}
+
+type newsFeedItem struct {
+	URL  string
+	GUID string
+	Date time.Time
+}
+
 type BlackListElement struct {
 	ID string
 }
@@ -67,7 +127,7 @@ func NewDBSession(dbname string) *DBWorker {
 
 // InitGuilds initialize guilds in database
 func (db *DBWorker) InitGuilds(sess *discordgo.Session, conf *Config) *GuildsMap {
-	var data = &GuildsMap{Guilds: make(map[string]*GuildData)}
+	var data = &GuildsMap{guilds: make(map[string]*GuildData)}
 	var loaded, initialized = 0, 0
 	for _, guild := range sess.State.Guilds {
 		count, err := db.DBSession.DB(db.DBName).C("guilds").Find(bson.M{"id": guild.ID}).Count()
@@ -76,17 +136,18 @@ func (db *DBWorker) InitGuilds(sess *discordgo.Session, conf *Config) *GuildsMap
 		}
 		if count == 0 {
 			newData := &GuildData{
-				ID:          guild.ID,
-				WeatherCity: conf.Weather.City,
-				NewsCounty:  conf.News.Country,
-				Language:    conf.General.Language,
-				Timezone:    conf.General.Timezone,
-				EmbedColor:  conf.General.EmbedColor,
-				VoiceVolume: conf.Voice.Volume,
-				Greeting:    "",
+				ID:           guild.ID,
+				WeatherCity:  conf.Weather.City,
+				NewsCounty:   conf.News.Country,
+				NewsLanguage: conf.News.Language,
+				Language:     conf.General.Language,
+				Timezone:     conf.General.Timezone,
+				EmbedColor:   conf.General.EmbedColor,
+				VoiceVolume:  conf.Voice.Volume,
+				Greeting:     "",
 			}
 			_ = db.DBSession.DB(db.DBName).C("guilds").Insert(newData)
-			data.Guilds[guild.ID] = newData
+			data.guilds[guild.ID] = newData
 			initialized++
 		} else {
 			var newData = &GuildData{}
@@ -95,7 +156,7 @@ func (db *DBWorker) InitGuilds(sess *discordgo.Session, conf *Config) *GuildsMap
 				fmt.Printf("Mongo: guilds, DB: %s, Guild: %s, Error: %v\n", db.DBName, guild.ID, err)
 				continue
 			}
-			data.Guilds[guild.ID] = newData
+			data.guilds[guild.ID] = newData
 			loaded++
 		}
 	}
@@ -106,17 +167,20 @@ func (db *DBWorker) InitGuilds(sess *discordgo.Session, conf *Config) *GuildsMap
 // InitNewGuild creates new guild in database
 func (db *DBWorker) InitNewGuild(guildID string, conf *Config, data *GuildsMap) {
 	newData := &GuildData{
-		ID:          guildID,
-		WeatherCity: conf.Weather.City,
-		NewsCounty:  conf.News.Country,
-		Language:    conf.General.Language,
-		Timezone:    conf.General.Timezone,
-		EmbedColor:  conf.General.EmbedColor,
-		VoiceVolume: conf.Voice.Volume,
-		Greeting:    "",
+		ID:           guildID,
+		WeatherCity:  conf.Weather.City,
+		NewsCounty:   conf.News.Country,
+		NewsLanguage: conf.News.Language,
+		Language:     conf.General.Language,
+		Timezone:     conf.General.Timezone,
+		EmbedColor:   conf.General.EmbedColor,
+		VoiceVolume:  conf.Voice.Volume,
+		Greeting:     "",
+	}
+	if data.add(newData) != newData {
+		return
 	}
 	_ = db.DBSession.DB(db.DBName).C("guilds").Insert(newData)
-	data.Guilds[guildID] = newData
 }
 
 // Log saves log in database
@@ -126,11 +190,23 @@ func (db *DBWorker) Log(module, guildID, text string) {
 
 // LogGet returns last N log rows
 func (db *DBWorker) LogGet(count int) []dbLog {
+	return db.LogGetPage(0, count)
+}
+
+// LogGetPage returns N log rows before last skipped rows
+func (db *DBWorker) LogGetPage(skip, count int) []dbLog {
 	var log = make([]dbLog, count)
-	_ = db.DBSession.DB(db.DBName).C("logs").Find(nil).Sort("-$natural").Limit(count).All(&log)
+	_ = db.DBSession.DB(db.DBName).C("logs").Find(nil).Sort("-$natural").Skip(skip).Limit(count).All(&log)
 	return log
 }
 
+// Ping checks connection to mongodb
+func (db *DBWorker) Ping() error {
+	session := db.DBSession.Copy()
+	defer session.Close()
+	return session.Ping()
+}
+
 // Guilds returns guilds collection from mongodb
 func (db *DBWorker) Guilds() *mgo.Collection {
 	return db.DBSession.DB(db.DBName).C("guilds")
@@ -155,7 +231,30 @@ func (db *DBWorker) UpdateStream(stream *TwitchStream) {
 	err := db.DBSession.DB(db.DBName).C("streams").
 		Update(
 			bson.M{"guild": stream.Guild, "login": stream.Login},
-			bson.M{"$set": bson.M{"isonline": stream.IsOnline}})
+			bson.M{"$set": bson.M{
+				"isonline":    stream.IsOnline,
+				"messageid":   stream.MessageID,
+				"streamid":    stream.StreamID,
+				"startedat":   stream.StartedAt,
+				"peakviewers": stream.PeakViewers,
+				"title":       stream.Title,
+				"gamename":    stream.GameName,
+				"language":    stream.Language}})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+// UpdateStreamOptions updates announcement options of stream in mongodb
+func (db *DBWorker) UpdateStreamOptions(stream *TwitchStream) {
+	err := db.DBSession.DB(db.DBName).C("streams").
+		Update(
+			bson.M{"guild": stream.Guild, "login": stream.Login},
+			bson.M{"$set": bson.M{
+				"iscustom":       stream.IsCustom,
+				"custommessage":  stream.CustomMessage,
+				"customimageurl": stream.CustomImageURL,
+				"mentionrole":    stream.MentionRole}})
 	if err != nil {
 		fmt.Println(err.Error())
 	}
@@ -171,6 +270,44 @@ func (db *DBWorker) RemoveStream(stream *TwitchStream) {
 	_ = db.DBSession.DB(db.DBName).C("streams").Remove(bson.M{"login": stream.Login, "guild": stream.Guild})
 }
 
+// GetYoutubeChannels returns YouTube channels subscriptions from mongodb
+func (db *DBWorker) GetYoutubeChannels(guildID string) map[string]*YoutubeChannel {
+	channels := []YoutubeChannel{}
+	err := db.DBSession.DB(db.DBName).C("ytchannels").Find(bson.M{"guild": guildID}).All(&channels)
+	if err != nil {
+		fmt.Printf("Mongo: ytchannels, DB: %s, Guild: %s, Error: %v\n", db.DBName, guildID, err)
+	}
+	var newMap = make(map[string]*YoutubeChannel)
+	for i, c := range channels {
+		newMap[c.ChannelID] = &channels[i]
+	}
+	return newMap
+}
+
+// UpdateYoutubeChannel updates last announced video of YouTube channel in mongodb
+func (db *DBWorker) UpdateYoutubeChannel(channel *YoutubeChannel) {
+	err := db.DBSession.DB(db.DBName).C("ytchannels").
+		Update(
+			bson.M{"guild": channel.Guild, "channelid": channel.ChannelID},
+			bson.M{"$set": bson.M{
+				"name":          channel.Name,
+				"lastvideo":     channel.LastVideo,
+				"lastpublished": channel.LastPublished}})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+// AddYoutubeChannel adds YouTube channel subscription in mongodb
+func (db *DBWorker) AddYoutubeChannel(channel *YoutubeChannel) {
+	_ = db.DBSession.DB(db.DBName).C("ytchannels").Insert(channel)
+}
+
+// RemoveYoutubeChannel removes YouTube channel subscription from mongodb
+func (db *DBWorker) RemoveYoutubeChannel(channel *YoutubeChannel) {
+	_ = db.DBSession.DB(db.DBName).C("ytchannels").Remove(bson.M{"channelid": channel.ChannelID, "guild": channel.Guild})
+}
+
 // GetRadioStations gets stations from database and returns slice of them
 func (db *DBWorker) GetRadioStations(category string) []RadioStation {
 	stations := []RadioStation{}
@@ -226,25 +363,185 @@ func (db *DBWorker) AddAlbionPlayer(player *AlbionPlayerUpdater) {
 	}
 }
 
-// RemoveAlbionPlayer removes player from database
-func (db *DBWorker) RemoveAlbionPlayer(id string) {
-	err := db.DBSession.DB(db.DBName).C("albion").Remove(bson.M{"userid": id})
+// RemoveAlbionPlayer removes player watched by user from database
+func (db *DBWorker) RemoveAlbionPlayer(userID, playerID string) {
+	err := db.DBSession.DB(db.DBName).C("albion").Remove(bson.M{"userid": userID, "playerid": playerID})
 	if err != nil {
 		fmt.Println("Error removing Albion player: ", err.Error())
 	}
 }
 
 // UpdateAlbionPlayerLast updates last kill of albion player
-func (db *DBWorker) UpdateAlbionPlayerLast(userID string, lastKill int64) {
+func (db *DBWorker) UpdateAlbionPlayerLast(userID, playerID string, lastKill int64) {
 	err := db.DBSession.DB(db.DBName).C("albion").
 		Update(
-			bson.M{"userid": userID},
+			bson.M{"userid": userID, "playerid": playerID},
 			bson.M{"$set": bson.M{"lastkill": lastKill}})
 	if err != nil {
 		fmt.Println(err.Error())
 	}
 }
 
+// UpdateAlbionPlayerChannel updates private channel of user watching albion player
+func (db *DBWorker) UpdateAlbionPlayerChannel(player *AlbionPlayerUpdater) {
+	err := db.DBSession.DB(db.DBName).C("albion").
+		Update(
+			bson.M{"userid": player.UserID, "playerid": player.PlayerID},
+			bson.M{"$set": bson.M{"channel": player.Channel}})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+// GetAlbionWatches gets watched Albion guilds and alliances from database
+func (db *DBWorker) GetAlbionWatches() []AlbionWatch {
+	var watches []AlbionWatch
+	err := db.DBSession.DB(db.DBName).C("albionwatch").Find(nil).All(&watches)
+	if err != nil {
+		fmt.Printf("Mongo: albionwatch, DB: %s, Error: %v\n", db.DBName, err)
+	}
+	return watches
+}
+
+// AddAlbionWatch adds watched Albion guild or alliance in database
+func (db *DBWorker) AddAlbionWatch(watch *AlbionWatch) {
+	err := db.DBSession.DB(db.DBName).C("albionwatch").Insert(watch)
+	if err != nil {
+		fmt.Println("Error adding Albion watch: ", err.Error())
+	}
+}
+
+// UpdateAlbionWatch updates last posted event of watched Albion guild or alliance
+func (db *DBWorker) UpdateAlbionWatch(watch *AlbionWatch) {
+	err := db.DBSession.DB(db.DBName).C("albionwatch").
+		Update(
+			bson.M{"channel": watch.Channel, "id": watch.ID},
+			bson.M{"$set": bson.M{"lastevent": watch.LastEvent}})
+	if err != nil {
+		fmt.Println("Error updating Albion watch: ", err.Error())
+	}
+}
+
+// RemoveAlbionWatch removes watched Albion guild or alliance of channel from database
+func (db *DBWorker) RemoveAlbionWatch(watch *AlbionWatch) {
+	err := db.DBSession.DB(db.DBName).C("albionwatch").Remove(bson.M{"channel": watch.Channel, "id": watch.ID})
+	if err != nil {
+		fmt.Println("Error removing Albion watch: ", err.Error())
+	}
+}
+
+// GetUserData returns user settings from database
+func (db *DBWorker) GetUserData(id string) (*UserData, error) {
+	var data UserData
+	err := db.DBSession.DB(db.DBName).C("users").Find(bson.M{"id": id}).One(&data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// SetUserData saves user settings in database
+func (db *DBWorker) SetUserData(data *UserData) error {
+	_, err := db.DBSession.DB(db.DBName).C("users").Upsert(bson.M{"id": data.ID}, data)
+	if err != nil {
+		fmt.Println("Error saving user data: ", err.Error())
+	}
+	return err
+}
+
+// GetCurrencyAlerts returns currency alerts of user in order of adding. Returns all alerts if user id is empty
+func (db *DBWorker) GetCurrencyAlerts(userID string) []CurrencyAlert {
+	var alerts []CurrencyAlert
+	var request = bson.M{}
+	if userID != "" {
+		request = bson.M{"userid": userID}
+	}
+	// Sorted, so numbers of alerts list are the same as used by "remove N"
+	err := db.DBSession.DB(db.DBName).C("curalerts").Find(request).Sort("_id").All(&alerts)
+	if err != nil {
+		fmt.Println("Error getting currency alerts: ", err.Error())
+	}
+	return alerts
+}
+
+// AddCurrencyAlert adds currency alert in database
+func (db *DBWorker) AddCurrencyAlert(alert *CurrencyAlert) error {
+	err := db.DBSession.DB(db.DBName).C("curalerts").Insert(alert)
+	if err != nil {
+		fmt.Println("Error adding currency alert: ", err.Error())
+	}
+	return err
+}
+
+// RemoveCurrencyAlert removes currency alert from database
+func (db *DBWorker) RemoveCurrencyAlert(alert *CurrencyAlert) error {
+	return db.DBSession.DB(db.DBName).C("curalerts").Remove(currencyAlertQuery(alert))
+}
+
+// UpdateCurrencyAlert updates state of currency alert
+func (db *DBWorker) UpdateCurrencyAlert(alert *CurrencyAlert) {
+	err := db.DBSession.DB(db.DBName).C("curalerts").
+		Update(
+			currencyAlertQuery(alert),
+			bson.M{"$set": bson.M{"triggered": alert.Triggered, "lastfired": alert.LastFired}})
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+func currencyAlertQuery(alert *CurrencyAlert) bson.M {
+	return bson.M{
+		"userid":   alert.UserID,
+		"currency": alert.Currency,
+		"target":   alert.Target,
+		"operator": alert.Operator,
+		"value":    alert.Value,
+	}
+}
+
+// GetNewsFeeds returns feed subscriptions of guild. Returns all subscriptions if guild id is empty
+func (db *DBWorker) GetNewsFeeds(guildID string) []NewsFeed {
+	var feeds []NewsFeed
+	var request = bson.M{}
+	if guildID != "" {
+		request = bson.M{"guild": guildID}
+	}
+	err := db.DBSession.DB(db.DBName).C("feeds").Find(request).All(&feeds)
+	if err != nil {
+		fmt.Printf("Mongo: feeds, DB: %s, Guild: %s, Error: %v\n", db.DBName, guildID, err)
+	}
+	return feeds
+}
+
+// AddNewsFeed adds feed subscription in database
+func (db *DBWorker) AddNewsFeed(feed *NewsFeed) error {
+	return db.DBSession.DB(db.DBName).C("feeds").Insert(feed)
+}
+
+// RemoveNewsFeed removes feed subscription of channel from database
+func (db *DBWorker) RemoveNewsFeed(channelID, url string) error {
+	return db.DBSession.DB(db.DBName).C("feeds").Remove(bson.M{"channel": channelID, "url": url})
+}
+
+// IsFeedItemSeen returns true if feed item already posted
+func (db *DBWorker) IsFeedItemSeen(url, guid string) bool {
+	count, err := db.DBSession.DB(db.DBName).C("feeditems").Find(bson.M{"url": url, "guid": guid}).Count()
+	if err != nil {
+		fmt.Println("Error checking feed item: ", err.Error())
+		// Better to skip item than to post it again
+		return true
+	}
+	return count > 0
+}
+
+// AddFeedItemSeen marks feed item as posted
+func (db *DBWorker) AddFeedItemSeen(url, guid string) {
+	err := db.DBSession.DB(db.DBName).C("feeditems").Insert(newsFeedItem{URL: url, GUID: guid, Date: time.Now()})
+	if err != nil {
+		fmt.Println("Error adding feed item: ", err.Error())
+	}
+}
+
 // GetBlackList gets blacklist from database
 func (db *DBWorker) GetBlacklist() *BlackListStruct {
 	var (
@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/FlameInTheDark/dtbot/api/geocoding"
+)
+
+// NewGeocoder creates geocoding service with providers from config
+func NewGeocoder(conf *Config, db *DBWorker) *geocoding.Service {
+	var providers []geocoding.Provider
+	names := conf.Geocoding.Providers
+	if len(names) == 0 {
+		names = []string{"geonames", "yandex", "nominatim"}
+	}
+	for _, name := range names {
+		switch name {
+		case "geonames":
+			providers = append(providers, &geocoding.GeoNames{Username: conf.General.GeonamesUsername})
+		case "yandex":
+			providers = append(providers, &geocoding.Yandex{APIKey: conf.General.GeocodingApiKey})
+		case "nominatim":
+			providers = append(providers, &geocoding.Nominatim{UserAgent: conf.Geocoding.UserAgent})
+		default:
+			fmt.Printf("Unknown geocoding provider \"%v\"\n", name)
+		}
+	}
+	return geocoding.New(geocoding.NewMongoCache(db.DBSession.DB(db.DBName).C("geocache")), providers...)
+}
+
+// Geocode returns location of place
+func (ctx *Context) Geocode(query string) (*geocoding.Location, error) {
+	return ctx.Geocoder.Geocode(ctx.CallContext(), query)
+}
@@ -2,7 +2,6 @@ package bot
 
 import (
 	"github.com/bwmarrin/discordgo"
-	"github.com/globalsign/mgo/bson"
 )
 
 // Greetings sends greetings for user
@@ -21,13 +20,10 @@ func Greetings(discord *discordgo.Session, event *discordgo.GuildMemberAdd, guil
 
 // AddGreetings adds new greetings to guild
 func (ctx *Context) AddGreetings(text string) {
-	ctx.Guilds.Guilds[ctx.Guild.ID].Greeting = text
-	_ = ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"greeting": text}})
+	_ = ctx.SetGuildSetting(SettingGreeting, text)
 }
 
 // RemoveGreetings removes greetings from guild
 func (ctx *Context) RemoveGreetings() {
-	ctx.Guilds.Guilds[ctx.Guild.ID].Greeting = ""
-	_ = ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"greeting": ""}})
-
+	_ = ctx.SetGuildSetting(SettingGreeting, "")
 }
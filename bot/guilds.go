@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"errors"
+)
+
+// Get returns settings of guild. Returned data is shared and must not be changed, use Update to change it
+func (m *GuildsMap) Get(guildID string) (*GuildData, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	guild, ok := m.guilds[guildID]
+	return guild, ok
+}
+
+// Update changes copy of guild settings and replaces settings with it if change returns no error.
+// Changes are made one by one, so change may save them in database in the same order
+func (m *GuildsMap) Update(guildID string, change func(guild *GuildData) error) error {
+	m.update.Lock()
+	defer m.update.Unlock()
+	current, ok := m.Get(guildID)
+	if !ok {
+		return errors.New("guild not found")
+	}
+	guild := current.copy()
+	if err := change(guild); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.guilds[guildID] = guild
+	m.mu.Unlock()
+	return nil
+}
+
+// add adds guild settings if guild has no settings yet and returns stored settings
+func (m *GuildsMap) add(guild *GuildData) *GuildData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.guilds[guild.ID]; ok {
+		return stored
+	}
+	m.guilds[guild.ID] = guild
+	return guild
+}
+
+// copy returns copy of guild settings with copied lists
+func (g *GuildData) copy() *GuildData {
+	guild := *g
+	guild.NewsSources = append([]string(nil), g.NewsSources...)
+	guild.NewsExcluded = append([]string(nil), g.NewsExcluded...)
+	guild.AutoTranslate = append([]AutoTranslateChannel(nil), g.AutoTranslate...)
+	return &guild
+}
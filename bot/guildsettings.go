@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// Keys of editable guild settings, same as keys of '!b setconf'
+const (
+	SettingLanguage     = "general.language"
+	SettingTimezone     = "general.timezone"
+	SettingWeatherCity  = "weather.city"
+	SettingNewsCountry  = "news.country"
+	SettingNewsLanguage = "news.language"
+	SettingEmbedColor   = "embed.color"
+	SettingVoiceVolume  = "voice.volume"
+	SettingGreeting     = "greeting"
+)
+
+// GuildSettingKeys keys of editable guild settings in display order
+var GuildSettingKeys = []string{
+	SettingLanguage,
+	SettingTimezone,
+	SettingWeatherCity,
+	SettingNewsCountry,
+	SettingNewsLanguage,
+	SettingEmbedColor,
+	SettingVoiceVolume,
+	SettingGreeting,
+}
+
+// GuildSettingNames readable names of guild settings
+var GuildSettingNames = map[string]string{
+	SettingLanguage:     "Language",
+	SettingTimezone:     "Timezone",
+	SettingWeatherCity:  "Weather city",
+	SettingNewsCountry:  "News country",
+	SettingNewsLanguage: "News language",
+	SettingEmbedColor:   "Embed color",
+	SettingVoiceVolume:  "Voice volume",
+	SettingGreeting:     "Greeting",
+}
+
+// Setting returns value of guild setting as text
+func (g *GuildData) Setting(key string) string {
+	switch key {
+	case SettingLanguage:
+		return g.Language
+	case SettingTimezone:
+		return strconv.Itoa(g.Timezone)
+	case SettingWeatherCity:
+		return g.WeatherCity
+	case SettingNewsCountry:
+		return g.NewsCounty
+	case SettingNewsLanguage:
+		return g.NewsLanguage
+	case SettingEmbedColor:
+		return fmt.Sprintf("#%06x", g.EmbedColor)
+	case SettingVoiceVolume:
+		return strconv.FormatFloat(float64(g.VoiceVolume*100), 'f', -1, 32)
+	case SettingGreeting:
+		return g.Greeting
+	}
+	return ""
+}
+
+// SetGuildSetting checks value of guild setting and saves it in database and then in memory
+func (db *DBWorker) SetGuildSetting(conf *Config, guilds *GuildsMap, guildID, key, value string) error {
+	value = strings.TrimSpace(value)
+	var (
+		field  string
+		stored interface{}
+		apply  func(guild *GuildData)
+	)
+	switch key {
+	case SettingLanguage:
+		if _, ok := conf.Locales[value]; !ok {
+			return fmt.Errorf("unknown language: %v", value)
+		}
+		field, stored, apply = "language", value, func(guild *GuildData) { guild.Language = value }
+	case SettingTimezone:
+		tz, err := strconv.Atoi(value)
+		if err != nil || tz < -12 || tz > 14 {
+			return fmt.Errorf("wrong timezone: %v", value)
+		}
+		field, stored, apply = "timezone", tz, func(guild *GuildData) { guild.Timezone = tz }
+	case SettingWeatherCity:
+		if value == "" {
+			return errors.New("city is empty")
+		}
+		field, stored, apply = "weathercity", value, func(guild *GuildData) { guild.WeatherCity = value }
+	case SettingNewsCountry:
+		if value == "" {
+			return errors.New("country is empty")
+		}
+		field, stored, apply = "newscounty", value, func(guild *GuildData) { guild.NewsCounty = value }
+	case SettingNewsLanguage:
+		field, stored, apply = "newslanguage", value, func(guild *GuildData) { guild.NewsLanguage = value }
+	case SettingEmbedColor:
+		color, err := strconv.ParseInt(strings.TrimPrefix(value, "#"), 16, 32)
+		if err != nil || color < 0 || color > 0xffffff {
+			return fmt.Errorf("wrong color: %v", value)
+		}
+		field, stored, apply = "embedcolor", int(color), func(guild *GuildData) { guild.EmbedColor = int(color) }
+	case SettingVoiceVolume:
+		vol, err := strconv.ParseFloat(value, 32)
+		if err != nil || vol < 0 {
+			return fmt.Errorf("wrong volume: %v", value)
+		}
+		volume := float32(vol * 0.01)
+		field, stored, apply = "voicevolume", volume, func(guild *GuildData) { guild.VoiceVolume = volume }
+	case SettingGreeting:
+		field, stored, apply = "greeting", value, func(guild *GuildData) { guild.Greeting = value }
+	default:
+		return fmt.Errorf("unknown setting: %v", key)
+	}
+	return guilds.Update(guildID, func(guild *GuildData) error {
+		if err := db.Guilds().Update(bson.M{"id": guildID}, bson.M{"$set": bson.M{field: stored}}); err != nil {
+			return err
+		}
+		apply(guild)
+		return nil
+	})
+}
+
+// SetGuildSetting checks value of current guild setting and saves it
+func (ctx *Context) SetGuildSetting(key, value string) error {
+	return ctx.DB.SetGuildSetting(ctx.Conf, ctx.Guilds, ctx.Guild.ID, key, value)
+}
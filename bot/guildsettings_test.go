@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+)
+
+func testGuilds() *GuildsMap {
+	return &GuildsMap{guilds: map[string]*GuildData{"1": {
+		ID:          "1",
+		Language:    "en",
+		Timezone:    3,
+		WeatherCity: "Moscow",
+		EmbedColor:  0x00ff7f,
+		VoiceVolume: 0.5,
+		NewsSources: []string{"bbc"},
+	}}}
+}
+
+func TestSetGuildSettingValidation(t *testing.T) {
+	conf := &Config{Locales: LocalesMap{"en": {}, "ru": {}}}
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{SettingLanguage, "xx"},
+		{SettingTimezone, "three"},
+		{SettingTimezone, "-13"},
+		{SettingTimezone, "15"},
+		{SettingWeatherCity, "  "},
+		{SettingNewsCountry, ""},
+		{SettingEmbedColor, "green"},
+		{SettingEmbedColor, "#1000000"},
+		{SettingEmbedColor, "-1"},
+		{SettingVoiceVolume, "loud"},
+		{SettingVoiceVolume, "-10"},
+		{"unknown.key", "value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			guilds := testGuilds()
+			before, _ := guilds.Get("1")
+			// database is not used, wrong values are rejected before saving
+			if err := (&DBWorker{}).SetGuildSetting(conf, guilds, "1", tt.key, tt.value); err == nil {
+				t.Fatalf("value %q of %v is accepted", tt.value, tt.key)
+			}
+			if after, _ := guilds.Get("1"); after != before {
+				t.Errorf("settings are changed after rejected value")
+			}
+		})
+	}
+	if err := (&DBWorker{}).SetGuildSetting(conf, testGuilds(), "2", SettingLanguage, "ru"); err == nil {
+		t.Error("setting of unknown guild is accepted")
+	}
+}
+
+func TestGuildSetting(t *testing.T) {
+	guild, _ := testGuilds().Get("1")
+	tests := map[string]string{
+		SettingLanguage:    "en",
+		SettingTimezone:    "3",
+		SettingWeatherCity: "Moscow",
+		SettingEmbedColor:  "#00ff7f",
+		SettingVoiceVolume: "50",
+		SettingGreeting:    "",
+		"unknown.key":      "",
+	}
+	for key, want := range tests {
+		if got := guild.Setting(key); got != want {
+			t.Errorf("Setting(%v) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestGuildsMapUpdate(t *testing.T) {
+	guilds := testGuilds()
+	before, _ := guilds.Get("1")
+
+	err := guilds.Update("1", func(guild *GuildData) error {
+		guild.Language = "ru"
+		return errors.New("database error")
+	})
+	if err == nil {
+		t.Fatal("error of change is not returned")
+	}
+	if current, _ := guilds.Get("1"); current != before || current.Language != "en" {
+		t.Fatal("failed change is saved")
+	}
+
+	err = guilds.Update("1", func(guild *GuildData) error {
+		guild.Language = "ru"
+		guild.NewsSources[0] = "cnn"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, _ := guilds.Get("1")
+	if current.Language != "ru" || current.NewsSources[0] != "cnn" {
+		t.Errorf("change is not saved: %+v", current)
+	}
+	if before.Language != "en" || before.NewsSources[0] != "bbc" {
+		t.Errorf("previous settings are changed: %+v", before)
+	}
+
+	if err := guilds.Update("2", func(*GuildData) error { return nil }); err == nil {
+		t.Error("unknown guild is updated")
+	}
+}
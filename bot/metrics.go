@@ -1,34 +1,30 @@
 package bot
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"net/http"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 )
 
 // MetricsCommand sends command metrics
 func (ctx *Context) MetricsCommand(command, state string) {
-	query := []byte(fmt.Sprintf("commands,server=%v,user=%v command=\"%v\",state=\"%v\"", ctx.Guild.ID, ctx.Message.Author.ID, command, state))
-	addr := fmt.Sprintf("%v/write?db=%v&u=%v&p=%v",
-		ctx.Conf.Metrics.Address, ctx.Conf.Metrics.Database, ctx.Conf.Metrics.User, ctx.Conf.Metrics.Password)
-	r := bytes.NewReader(query)
-	_, _ = http.Post(addr, "", r)
+	ctx.sendMetrics(fmt.Sprintf("commands,server=%v,user=%v command=\"%v\",state=\"%v\"", ctx.Guild.ID, ctx.Message.Author.ID, command, state))
 }
 
 // MetricsLog sends log metrics
 func (ctx *Context) MetricsLog(module string) {
-	query := []byte(fmt.Sprintf("logs,server=%v module=\"%v\"", ctx.Guild.ID, module))
-	addr := fmt.Sprintf("%v/write?db=%v&u=%v&p=%v",
-		ctx.Conf.Metrics.Address, ctx.Conf.Metrics.Database, ctx.Conf.Metrics.User, ctx.Conf.Metrics.Password)
-	r := bytes.NewReader(query)
-	_, _ = http.Post(addr, "", r)
+	ctx.sendMetrics(fmt.Sprintf("logs,server=%v module=\"%v\"", ctx.Guild.ID, module))
 }
 
 // MetricsMessage sends message metrics
 func (ctx *Context) MetricsMessage() {
-	query := []byte(fmt.Sprintf("messages,server=%v user=\"%v\"", ctx.Guild.ID, ctx.Message.Author.ID))
+	ctx.sendMetrics(fmt.Sprintf("messages,server=%v user=\"%v\"", ctx.Guild.ID, ctx.Message.Author.ID))
+}
+
+// sendMetrics writes query to metrics database
+func (ctx *Context) sendMetrics(query string) {
 	addr := fmt.Sprintf("%v/write?db=%v&u=%v&p=%v",
 		ctx.Conf.Metrics.Address, ctx.Conf.Metrics.Database, ctx.Conf.Metrics.User, ctx.Conf.Metrics.Password)
-	r := bytes.NewReader(query)
-	_, _ = http.Post(addr, "", r)
+	_, _ = httpclient.For("metrics").Post(context.Background(), addr, "", []byte(query))
 }
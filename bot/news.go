@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"errors"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// NewsSourceAllowed returns true if news source is allowed in guild
+func (g *GuildData) NewsSourceAllowed(source string) bool {
+	for _, s := range g.NewsExcluded {
+		if s == source {
+			return false
+		}
+	}
+	if len(g.NewsSources) == 0 {
+		return true
+	}
+	for _, s := range g.NewsSources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowNewsSource adds source to guild news allow list and removes it from deny list
+func (ctx *Context) AllowNewsSource(source string) error {
+	return ctx.updateGuild(func(guild *GuildData) error {
+		guild.NewsExcluded = removeString(guild.NewsExcluded, source)
+		if !containsString(guild.NewsSources, source) {
+			guild.NewsSources = append(guild.NewsSources, source)
+		}
+		return ctx.updateNewsSources(guild)
+	})
+}
+
+// DenyNewsSource adds source to guild news deny list and removes it from allow list
+func (ctx *Context) DenyNewsSource(source string) error {
+	return ctx.updateGuild(func(guild *GuildData) error {
+		guild.NewsSources = removeString(guild.NewsSources, source)
+		if !containsString(guild.NewsExcluded, source) {
+			guild.NewsExcluded = append(guild.NewsExcluded, source)
+		}
+		return ctx.updateNewsSources(guild)
+	})
+}
+
+// ResetNewsSource removes source from guild news allow and deny lists
+func (ctx *Context) ResetNewsSource(source string) error {
+	return ctx.updateGuild(func(guild *GuildData) error {
+		if !containsString(guild.NewsSources, source) && !containsString(guild.NewsExcluded, source) {
+			return errors.New("source not found")
+		}
+		guild.NewsSources = removeString(guild.NewsSources, source)
+		guild.NewsExcluded = removeString(guild.NewsExcluded, source)
+		return ctx.updateNewsSources(guild)
+	})
+}
+
+// SetNewsLanguage sets language of guild news search
+func (ctx *Context) SetNewsLanguage(language string) error {
+	return ctx.SetGuildSetting(SettingNewsLanguage, language)
+}
+
+func (ctx *Context) updateNewsSources(guild *GuildData) error {
+	return ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"newssources": guild.NewsSources, "newsexcluded": guild.NewsExcluded}})
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, str string) []string {
+	var result []string
+	for _, s := range list {
+		if s != str {
+			result = append(result, s)
+		}
+	}
+	return result
+}
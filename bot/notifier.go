@@ -0,0 +1,217 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// NotifyEventType kind of change detected by notifier
+type NotifyEventType int
+
+const (
+	// NotifyStarted new item appeared: stream started, video uploaded or feed entry published
+	NotifyStarted NotifyEventType = iota
+	// NotifyUpdated live item changed, announcement is edited
+	NotifyUpdated
+	// NotifyEnded live item ended, announcement is edited
+	NotifyEnded
+)
+
+// notifyTick precision of notifiers schedule
+const notifyTick = 10 * time.Second
+
+// NotifySubscription subscription of Discord channel to subject of notifier
+type NotifySubscription interface {
+	// Subject key of watched subject: Twitch user ID, YouTube channel ID or feed URL
+	Subject() string
+	// Target ID of Discord channel for announcements
+	Target() string
+}
+
+// NotifyItem current state of item published by subject
+type NotifyItem struct {
+	ID string
+	// Live item can change and end, like stream
+	Live bool
+	// Revision changes when announcement of live item should be updated
+	Revision string
+	// Data source specific item data
+	Data interface{}
+}
+
+// NotifyEvent change of item that should be announced
+type NotifyEvent struct {
+	Type         NotifyEventType
+	Subscription NotifySubscription
+	Item         NotifyItem
+	// MessageID announcement to edit on update or end
+	MessageID string
+}
+
+// NotifySource polls external service and returns current items by subject,
+// subjects missing in result are not checked
+type NotifySource interface {
+	Poll(ctx context.Context, subjects []string) (map[string][]NotifyItem, error)
+}
+
+// NotifyFilter optionally implemented by source to skip subscriptions updated without polling
+type NotifyFilter interface {
+	Watch(sub NotifySubscription) bool
+}
+
+// NotifyDetector compares items with state of subscriptions and returns changes,
+// state of subscriptions is updated by detector
+type NotifyDetector interface {
+	Detect(subject string, subs []NotifySubscription, items []NotifyItem) []NotifyEvent
+}
+
+// NotifyRenderer makes message of event
+type NotifyRenderer interface {
+	Render(ctx context.Context, event NotifyEvent) *NewEmbedStruct
+}
+
+// NotifyStore loads subscriptions and saves their state
+type NotifyStore interface {
+	Subscriptions() []NotifySubscription
+	// Save persists state of subscription after event, messageID is ID of sent or edited announcement
+	Save(event NotifyEvent, messageID string)
+}
+
+// Notifier checks source and announces changes in Discord channels
+type Notifier struct {
+	Name     string
+	Interval time.Duration
+	Source   NotifySource
+	Detector NotifyDetector
+	Renderer NotifyRenderer
+	Store    NotifyStore
+	Discord  *discordgo.Session
+	DB       *DBWorker
+
+	// mu prevents concurrent checks of scheduler and pushed updates
+	mu sync.Mutex
+}
+
+// Check polls all subscriptions of notifier
+func (n *Notifier) Check(ctx context.Context) {
+	n.CheckSubjects(ctx)
+}
+
+// CheckSubjects polls subscriptions of specified subjects, all subscriptions if subjects are not specified
+func (n *Notifier) CheckSubjects(ctx context.Context, subjects ...string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var only = make(map[string]bool)
+	for _, s := range subjects {
+		only[s] = true
+	}
+	filter, hasFilter := n.Source.(NotifyFilter)
+	var subs = make(map[string][]NotifySubscription)
+	var keys []string
+	for _, sub := range n.Store.Subscriptions() {
+		if len(only) > 0 && !only[sub.Subject()] {
+			continue
+		}
+		if len(only) == 0 && hasFilter && !filter.Watch(sub) {
+			continue
+		}
+		if _, ok := subs[sub.Subject()]; !ok {
+			keys = append(keys, sub.Subject())
+		}
+		subs[sub.Subject()] = append(subs[sub.Subject()], sub)
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	items, err := n.Source.Poll(ctx, keys)
+	if err != nil {
+		n.DB.Log(n.Name, "", fmt.Sprintf("Notifier poll error: %v", err))
+		return
+	}
+	for subject, current := range items {
+		for _, event := range n.Detector.Detect(subject, subs[subject], current) {
+			n.announce(ctx, event)
+		}
+	}
+}
+
+// announce sends announcement of started item or edits announcement of updated or ended item
+func (n *Notifier) announce(ctx context.Context, event NotifyEvent) {
+	var messageID string
+	switch {
+	case event.Type == NotifyStarted:
+		msg, err := n.Discord.ChannelMessageSendComplex(event.Subscription.Target(), n.Renderer.Render(ctx, event).MessageSend)
+		if err != nil {
+			n.DB.Log(n.Name, "", fmt.Sprintf("Notifier send error: %v", err))
+		} else {
+			messageID = msg.ID
+		}
+	case event.MessageID != "":
+		emb := n.Renderer.Render(ctx, event)
+		_, err := n.Discord.ChannelMessageEditComplex(discordgo.NewMessageEdit(event.Subscription.Target(), event.MessageID).
+			SetContent(emb.Content).
+			SetEmbed(emb.GetEmbed()))
+		if err != nil {
+			n.DB.Log(n.Name, "", fmt.Sprintf("Notifier edit error: %v", err))
+		}
+		messageID = event.MessageID
+	}
+	n.Store.Save(event, messageID)
+}
+
+// NotifyScheduler runs notifiers in their intervals
+type NotifyScheduler struct {
+	notifiers []*Notifier
+}
+
+// NewNotifyScheduler creates scheduler of notifiers
+func NewNotifyScheduler(notifiers ...*Notifier) *NotifyScheduler {
+	return &NotifyScheduler{notifiers: notifiers}
+}
+
+// Add adds notifier to scheduler, must be called before Run
+func (s *NotifyScheduler) Add(n *Notifier) {
+	s.notifiers = append(s.notifiers, n)
+}
+
+// Run checks notifiers when their interval passes, check is skipped if previous one is not finished
+func (s *NotifyScheduler) Run() {
+	var (
+		next    = make([]time.Time, len(s.notifiers))
+		running = make([]bool, len(s.notifiers))
+		mu      sync.Mutex
+	)
+	ticker := time.NewTicker(notifyTick)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		for i, n := range s.notifiers {
+			mu.Lock()
+			due := !running[i] && !now.Before(next[i])
+			if due {
+				running[i] = true
+				next[i] = now.Add(n.Interval)
+			}
+			mu.Unlock()
+			if !due {
+				continue
+			}
+			go func(i int, n *Notifier) {
+				defer func() {
+					if r := recover(); r != nil {
+						n.DB.Log(n.Name, "", fmt.Sprintf("Notifier panic: %v", r))
+					}
+					mu.Lock()
+					running[i] = false
+					mu.Unlock()
+				}()
+				n.Check(context.Background())
+			}(i, n)
+		}
+	}
+}
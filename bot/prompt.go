@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	confirmYesEmoji = "✅"
+	confirmNoEmoji  = "❌"
+	// promptTimeout default time in seconds of waiting for user answer
+	promptTimeout = 30
+)
+
+// ErrPromptTimeout returns when user did not answer in time
+var ErrPromptTimeout = errors.New("prompt timeout")
+
+func (ctx *Context) promptTimeout() time.Duration {
+	if ctx.Conf.General.PromptTimeout > 0 {
+		return time.Duration(ctx.Conf.General.PromptTimeout) * time.Second
+	}
+	return promptTimeout * time.Second
+}
+
+// Confirm asks user a question and waits for confirmation by reaction or reply,
+// returns false if user declined or did not answer in time
+func (ctx *Context) Confirm(question string) bool {
+	msg := ctx.ReplyEmbed(ctx.Loc("confirm"), question+"\n\n"+ctx.Loc("confirm_hint"))
+	if msg == nil {
+		return false
+	}
+	_ = ctx.Discord.MessageReactionAdd(msg.ChannelID, msg.ID, confirmYesEmoji)
+	_ = ctx.Discord.MessageReactionAdd(msg.ChannelID, msg.ID, confirmNoEmoji)
+
+	answers := make(chan bool, 1)
+	answer := func(ok bool) {
+		select {
+		case answers <- ok:
+		default:
+		}
+	}
+	removeReaction := ctx.Discord.AddHandler(func(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+		if r.MessageID != msg.ID || r.UserID != ctx.User.ID {
+			return
+		}
+		switch r.Emoji.Name {
+		case confirmYesEmoji:
+			answer(true)
+		case confirmNoEmoji:
+			answer(false)
+		}
+	})
+	defer removeReaction()
+	removeReply := ctx.Discord.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		if m.ChannelID != msg.ChannelID || m.Author.ID != ctx.User.ID {
+			return
+		}
+		answer(ctx.isYes(m.Content))
+	})
+	defer removeReply()
+
+	var confirmed bool
+	select {
+	case confirmed = <-answers:
+	case <-time.After(ctx.promptTimeout()):
+	}
+	_ = ctx.Discord.MessageReactionsRemoveAll(msg.ChannelID, msg.ID)
+	if !confirmed {
+		ctx.EditEmbed(msg.ID, ctx.Loc("confirm"), ctx.Loc("confirm_cancelled"), false)
+	}
+	return confirmed
+}
+
+// Prompt asks user a question and returns text of user reply
+func (ctx *Context) Prompt(question string) (string, error) {
+	msg := ctx.ReplyEmbed(ctx.Loc("prompt"), question)
+	if msg == nil {
+		return "", errors.New("unable to send prompt")
+	}
+
+	replies := make(chan string, 1)
+	removeReply := ctx.Discord.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		if m.ChannelID != msg.ChannelID || m.Author.ID != ctx.User.ID {
+			return
+		}
+		select {
+		case replies <- m.Content:
+		default:
+		}
+	})
+	defer removeReply()
+
+	select {
+	case reply := <-replies:
+		return reply, nil
+	case <-time.After(ctx.promptTimeout()):
+		ctx.EditEmbed(msg.ID, ctx.Loc("prompt"), ctx.Loc("confirm_cancelled"), false)
+		return "", ErrPromptTimeout
+	}
+}
+
+// isYes returns true if text is positive answer in guild language
+func (ctx *Context) isYes(text string) bool {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for _, w := range strings.Split(ctx.Loc("confirm_yes_words"), ",") {
+		if text == strings.TrimSpace(w) {
+			return true
+		}
+	}
+	return false
+}
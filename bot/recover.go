@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// commandTimeout default time in seconds of external requests made by one command
+const commandTimeout = 30
+
+// RunCommand executes command with deadline of external requests and recovers it from panic,
+// panic is logged, counted in metrics and reported to user and bot owner
+func (ctx *Context) RunCommand(name string, command Command) {
+	timeout := time.Duration(ctx.Conf.HTTP.CommandTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = commandTimeout * time.Second
+	}
+	var cancel context.CancelFunc
+	ctx.callCtx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.reportPanic(name, r, debug.Stack())
+		}
+	}()
+	command(*ctx)
+}
+
+func (ctx *Context) reportPanic(name string, r interface{}, stack []byte) {
+	// reporting must not crash the bot too
+	defer func() {
+		if err := recover(); err != nil {
+			fmt.Println("Error whilst reporting panic: ", err)
+		}
+	}()
+	text := fmt.Sprintf("Command %v panic: %v\nMessage: %v\n%s", name, r, ctx.Message.Content, stack)
+	fmt.Println(text)
+	ctx.DB.Log("panic", ctx.Guild.ID, text)
+	ctx.MetricsCommand(name, "panic")
+	ctx.ReplyEmbed(ctx.Loc("error"), ctx.Loc("command_panic"))
+
+	if ctx.Conf.General.ErrorsChannel == "" {
+		return
+	}
+	emb := NewEmbed(fmt.Sprintf("Panic in %v", name)).
+		Desc(fmt.Sprintf("```%v```", TruncateText(string(stack), 1900))).
+		Field("Error", fmt.Sprint(r), false).
+		Field("Message", ctx.Message.Content, false).
+		Field("Guild", fmt.Sprintf("%v (%v)", ctx.Guild.Name, ctx.Guild.ID), true).
+		Field("User", fmt.Sprintf("%v (%v)", ctx.User.Username, ctx.User.ID), true).
+		Color(ctx.GetGuild().EmbedColor)
+	_, err := ctx.Discord.ChannelMessageSendEmbed(ctx.Conf.General.ErrorsChannel, emb.GetEmbed())
+	if err != nil {
+		fmt.Println("Error whilst sending panic report: ", err)
+	}
+}
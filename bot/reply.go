@@ -3,26 +3,39 @@ package bot
 import (
 	"fmt"
 	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+const (
+	pagePrevEmoji = "\u2b05\ufe0f"
+	pageNextEmoji = "\u27a1\ufe0f"
+	// pageTimeout default time in seconds while pages can be switched
+	pageTimeout = 120
+	// pageLength max length of page description
+	pageLength = 2000
+)
+
 // NewEmbedStruct generated embed
 type NewEmbedStruct struct {
 	*discordgo.MessageSend
 	embLength int
 }
 
-func truncateText(text string, length int) string {
-	if length > 3 && len(text) > length && len(text) > 3 {
-		text = text[:length-3] + "..."
+// TruncateText cuts text to length characters ending with "...", multibyte characters are not split
+func TruncateText(text string, length int) string {
+	if length <= 3 || utf8.RuneCountInString(text) <= length {
+		return text
 	}
-	return text
+	return string([]rune(text)[:length-3]) + "..."
 }
 
 // NewEmbed creates new embed
 func NewEmbed(title string) *NewEmbedStruct {
-	title = truncateText(title, 256)
+	title = TruncateText(title, 256)
 	return &NewEmbedStruct{&discordgo.MessageSend{Embed: &discordgo.MessageEmbed{Title: title}}, len(title)}
 }
 
@@ -37,8 +50,8 @@ func (emb *NewEmbedStruct) CheckLength(newLength int) bool {
 // Field adds field to embed
 func (emb *NewEmbedStruct) Field(name, value string, inline bool) *NewEmbedStruct {
 	if len(name) > 0 && len(value) > 0 {
-		name = truncateText(name, 256)
-		value = truncateText(value, 1024)
+		name = TruncateText(name, 256)
+		value = TruncateText(value, 1024)
 		newLength := len(name + value)
 		if emb.CheckLength(newLength) {
 			emb.Embed.Fields = append(emb.Embed.Fields,
@@ -60,7 +73,7 @@ func (emb *NewEmbedStruct) TimeStamp(ts string) *NewEmbedStruct {
 
 // Author adds author to embed
 func (emb *NewEmbedStruct) Author(name, url, iconURL string) *NewEmbedStruct {
-	name = truncateText(name, 256)
+	name = TruncateText(name, 256)
 	newLength := len(name)
 	if emb.CheckLength(newLength) {
 		emb.Embed.Author = &discordgo.MessageEmbedAuthor{URL: url, Name: name, IconURL: iconURL}
@@ -72,7 +85,7 @@ func (emb *NewEmbedStruct) Author(name, url, iconURL string) *NewEmbedStruct {
 // Desc adds description to embed
 func (emb *NewEmbedStruct) Desc(desc string) *NewEmbedStruct {
 	if len(desc) > 0 {
-		desc = truncateText(desc, 2048)
+		desc = TruncateText(desc, 2048)
 		newLength := len(desc)
 		if emb.CheckLength(newLength) {
 			emb.Embed.Description = desc
@@ -90,7 +103,7 @@ func (emb *NewEmbedStruct) URL(url string) *NewEmbedStruct {
 
 // Footer adds footer text
 func (emb *NewEmbedStruct) Footer(text string) *NewEmbedStruct {
-	text = truncateText(text, 2048)
+	text = TruncateText(text, 2048)
 	newLength := len(text)
 	if emb.CheckLength(newLength) {
 		emb.Embed.Footer = &discordgo.MessageEmbedFooter{Text: text}
@@ -239,4 +252,137 @@ func (ctx *Context) ReplyEmbedAttachmentImageURL(title, desc, imageUrl string) *
 		Footer(ctx.Loc("requested_by") + ": " + ctx.User.Username).
 		Color(ctx.GetGuild().EmbedColor).
 		Send(ctx)
-}
\ No newline at end of file
+}
+
+// ListPages splits lines to embed pages with specified count of lines per page
+func ListPages(title string, lines []string, perPage int) []*NewEmbedStruct {
+	var (
+		pages []*NewEmbedStruct
+		page  []string
+		size  int
+	)
+	for _, l := range lines {
+		if len(page) > 0 && (len(page) >= perPage || size+len(l) > pageLength) {
+			pages = append(pages, NewEmbed(title).Desc(strings.Join(page, "\n")))
+			page, size = nil, 0
+		}
+		page = append(page, l)
+		size += len(l) + 1
+	}
+	if len(page) > 0 {
+		pages = append(pages, NewEmbed(title).Desc(strings.Join(page, "\n")))
+	}
+	return pages
+}
+
+// paginator switches pages of sent embed by reactions of user
+type paginator struct {
+	ctx     *Context
+	pages   []*NewEmbedStruct
+	current int
+	message *discordgo.Message
+	pm      bool
+}
+
+// SendPages sends first page to channel and lets user switch pages by reactions
+func (ctx *Context) SendPages(pages []*NewEmbedStruct) *discordgo.Message {
+	if len(pages) == 0 {
+		return nil
+	}
+	p := &paginator{ctx: ctx, pages: pages}
+	p.footers(ctx.Loc("requested_by") + ": " + ctx.User.Username)
+	p.message = pages[0].Send(ctx)
+	p.start()
+	return p.message
+}
+
+// SendPagesPM sends first page to user personal channel and lets user switch pages by reactions
+func (ctx *Context) SendPagesPM(pages []*NewEmbedStruct) *discordgo.Message {
+	if len(pages) == 0 {
+		return nil
+	}
+	p := &paginator{ctx: ctx, pages: pages, pm: true}
+	p.footers(ctx.Loc("requested_from") + ": " + ctx.Guild.Name)
+	p.message = pages[0].SendPM(ctx)
+	p.start()
+	return p.message
+}
+
+func (p *paginator) footers(text string) {
+	for i, page := range p.pages {
+		if len(p.pages) > 1 {
+			page.Footer(fmt.Sprintf("%v | %v %v/%v", text, p.ctx.Loc("page"), i+1, len(p.pages)))
+		} else {
+			page.Footer(text)
+		}
+		page.Color(p.ctx.GetGuild().EmbedColor)
+	}
+}
+
+func (p *paginator) start() {
+	if p.message == nil || len(p.pages) < 2 {
+		return
+	}
+	_ = p.ctx.Discord.MessageReactionAdd(p.message.ChannelID, p.message.ID, pagePrevEmoji)
+	_ = p.ctx.Discord.MessageReactionAdd(p.message.ChannelID, p.message.ID, pageNextEmoji)
+	go p.run()
+}
+
+func (p *paginator) run() {
+	var (
+		events  = make(chan string, 1)
+		timeout = time.Duration(p.ctx.Conf.General.PagesTimeout) * time.Second
+	)
+	if timeout <= 0 {
+		timeout = pageTimeout * time.Second
+	}
+	// push sends reaction of command author on paginated message, returns false for other reactions
+	push := func(r *discordgo.MessageReaction) bool {
+		if r.MessageID != p.message.ID || r.UserID != p.ctx.User.ID {
+			return false
+		}
+		select {
+		case events <- r.Emoji.Name:
+		default:
+		}
+		return true
+	}
+	removeAdd := p.ctx.Discord.AddHandler(func(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+		if push(r.MessageReaction) && !p.pm {
+			_ = s.MessageReactionRemove(r.ChannelID, r.MessageID, r.Emoji.Name, r.UserID)
+		}
+	})
+	defer removeAdd()
+	// bot can not remove reactions in personal channel, so removing of reaction switches page too
+	if p.pm {
+		removeDel := p.ctx.Discord.AddHandler(func(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+			push(r.MessageReaction)
+		})
+		defer removeDel()
+	}
+
+	timer := time.NewTimer(timeout)
+	for {
+		select {
+		case emoji := <-events:
+			switch emoji {
+			case pagePrevEmoji, strings.TrimSuffix(pagePrevEmoji, "\ufe0f"):
+				p.current = (p.current + len(p.pages) - 1) % len(p.pages)
+			case pageNextEmoji, strings.TrimSuffix(pageNextEmoji, "\ufe0f"):
+				p.current = (p.current + 1) % len(p.pages)
+			default:
+				continue
+			}
+			_, err := p.ctx.Discord.ChannelMessageEditEmbed(p.message.ChannelID, p.message.ID, p.pages[p.current].GetEmbed())
+			if err != nil {
+				fmt.Println("Error whilst switching page, ", err)
+			}
+			timer.Reset(timeout)
+		case <-timer.C:
+			if !p.pm {
+				_ = p.ctx.Discord.MessageReactionsRemoveAll(p.message.ChannelID, p.message.ID)
+			}
+			return
+		}
+	}
+}
@@ -16,13 +16,46 @@ func (ctx *Context) IsAdmin() bool {
 	return ctx.User.ID == ctx.Conf.General.AdminID
 }
 
+// IsServerAdmin returns true if user can manage bot in current guild
 func (ctx *Context) IsServerAdmin() bool {
-	if ctx.User.ID == ctx.Guild.OwnerID || ctx.GetRoles().ExistsName("bot.admin") || ctx.IsAdmin() {
+	return IsGuildAdmin(ctx.Discord, ctx.Conf, ctx.Guild, ctx.User.ID)
+}
+
+// IsGuildAdmin returns true if user is owner of guild, has 'bot.admin' role or is bot admin,
+// same rules as server admin commands
+func IsGuildAdmin(discord *discordgo.Session, conf *Config, guild *discordgo.Guild, userID string) bool {
+	if userID == guild.OwnerID || userID == conf.General.AdminID {
 		return true
 	}
+	// members of state are used first, so checks of many guilds do not hit API rate limits
+	memb, err := discord.State.Member(guild.ID, userID)
+	if err != nil {
+		memb, err = discord.GuildMember(guild.ID, userID)
+		if err != nil {
+			return false
+		}
+	}
+	for _, grole := range guild.Roles {
+		for _, urole := range memb.Roles {
+			if grole.ID == urole && grole.Name == "bot.admin" {
+				return true
+			}
+		}
+	}
 	return false
 }
 
+// HasPermission returns true if user has specified permission level
+func (ctx *Context) HasPermission(permission Permission) bool {
+	switch permission {
+	case PermissionServerAdmin:
+		return ctx.IsServerAdmin()
+	case PermissionBotAdmin:
+		return ctx.IsAdmin()
+	}
+	return true
+}
+
 // GetRoles returns UserRoles struct pointer
 func (ctx *Context) GetRoles() *UserRoles {
 	var userRoles = new(UserRoles)
@@ -1,6 +1,8 @@
 package bot
 
 import (
+	"sync"
+
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -11,11 +13,13 @@ type (
 		Player             RadioPlayer
 		guildID, ChannelID string
 		connection         *Connection
-		Volume             float32
+		mu                 sync.RWMutex
+		volume             float32
 	}
 
 	// SessionManager contains all sessions
 	SessionManager struct {
+		sync.RWMutex
 		sessions map[string]*Session
 	}
 
@@ -33,7 +37,7 @@ func newSession(newGuildID, newChannelID string, conn *Connection, volume float3
 		guildID:    newGuildID,
 		ChannelID:  newChannelID,
 		connection: conn,
-		Volume:     volume,
+		volume:     volume,
 	}
 	return session
 }
@@ -49,8 +53,22 @@ func (sess *Session) Play(source string, volume float32) error {
 }
 
 // PlayYoutube starts to play song from youtube
-func (sess Session) PlayYoutube(song Song) error {
-	return sess.connection.PlayYoutube(song.Ffmpeg(sess.Volume))
+func (sess *Session) PlayYoutube(song Song) error {
+	return sess.connection.PlayYoutube(song.Ffmpeg(sess.Volume()))
+}
+
+// Volume returns volume of youtube songs
+func (sess *Session) Volume() float32 {
+	sess.mu.RLock()
+	defer sess.mu.RUnlock()
+	return sess.volume
+}
+
+// SetVolume sets volume of next youtube songs
+func (sess *Session) SetVolume(volume float32) {
+	sess.mu.Lock()
+	sess.volume = volume
+	sess.mu.Unlock()
 }
 
 // Stop stops radio
@@ -60,11 +78,13 @@ func (sess *Session) Stop() {
 
 // NewSessionManager creates and returns new session manager
 func NewSessionManager() *SessionManager {
-	return &SessionManager{make(map[string]*Session)}
+	return &SessionManager{sessions: make(map[string]*Session)}
 }
 
 // GetByGuild returns session by guild ID
 func (manager *SessionManager) GetByGuild(guildID string) *Session {
+	manager.RLock()
+	defer manager.RUnlock()
 	for _, sess := range manager.sessions {
 		if sess.guildID == guildID {
 			return sess
@@ -75,6 +95,8 @@ func (manager *SessionManager) GetByGuild(guildID string) *Session {
 
 // GetByChannel returns session by channel ID
 func (manager *SessionManager) GetByChannel(channelID string) (*Session, bool) {
+	manager.RLock()
+	defer manager.RUnlock()
 	sess, found := manager.sessions[channelID]
 	return sess, found
 }
@@ -87,18 +109,24 @@ func (manager *SessionManager) Join(discord *discordgo.Session, guildID, channel
 		return nil, err
 	}
 	sess := newSession(guildID, channelID, NewConnection(vc), volume)
+	manager.Lock()
 	manager.sessions[channelID] = sess
+	manager.Unlock()
 	return sess, nil
 }
 
 // Leave remove bot from voice channel
-func (manager *SessionManager) Leave(discord *discordgo.Session, session Session) {
+func (manager *SessionManager) Leave(discord *discordgo.Session, session *Session) {
 	session.connection.Stop()
 	session.connection.Disconnect()
+	manager.Lock()
 	delete(manager.sessions, session.ChannelID)
+	manager.Unlock()
 }
 
 // Count returns count of voice sessions
 func (manager *SessionManager) Count() int {
+	manager.RLock()
+	defer manager.RUnlock()
 	return len(manager.sessions)
 }
@@ -1,22 +1,41 @@
 package bot
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 	"github.com/bwmarrin/discordgo"
+	"io"
 	"net/http"
 	"net/url"
-	"strings"
+	"sort"
+	"sync"
 	"time"
 )
 
+const (
+	twitchAPIURL = "https://api.twitch.tv/helix/"
+	// twitchMaxQuery max count of logins or IDs in one Helix API request
+	twitchMaxQuery = 100
+	// twitchInterval interval of streams polling
+	twitchInterval = time.Minute
+)
+
 // Twitch contains streams
 type Twitch struct {
+	sync.Mutex
 	Guilds  map[string]*TwitchGuild
 	DB      *DBWorker
 	Conf    *Config
 	Discord *discordgo.Session
+	// Notifier polls streams and sends announcements
+	Notifier *Notifier
+
+	token    twitchToken
+	eventSub *twitchEventSub
 }
 
 // TwitchGuild contains streams from specified guild
@@ -37,6 +56,16 @@ type TwitchStream struct {
 	IsCustom        bool
 	CustomMessage   string
 	CustomImageURL  string
+	// MentionRole ID of role mentioned in announcement
+	MentionRole string
+	// State of current broadcast, used to update announcement
+	MessageID   string
+	StreamID    string
+	StartedAt   time.Time
+	PeakViewers int
+	Title       string
+	GameName    string
+	Language    string
 }
 
 // TwitchStreamResult contains response of Twitch API for streams
@@ -55,6 +84,7 @@ type TwitchStreamData struct {
 	Viewers      int    `json:"viewer_count"`
 	Language     string `json:"language"`
 	ThumbnailURL string `json:"thumbnail_url"`
+	StartedAt    string `json:"started_at"`
 }
 
 // TwitchUserResult contains response of Twitch API for users
@@ -75,6 +105,20 @@ type TwitchUserData struct {
 	Views           int    `json:"view_count"`
 }
 
+// TwitchVideoResult contains response of Twitch API for videos
+type TwitchVideoResult struct {
+	Data []TwitchVideoData `json:"data"`
+}
+
+// TwitchVideoData Twitch API response struct
+type TwitchVideoData struct {
+	ID       string `json:"id"`
+	StreamID string `json:"stream_id"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Duration string `json:"duration"`
+}
+
 // TwitchGameResult contains response of Twitch API for games
 type TwitchGameResult struct {
 	Data []TwitchGameData `json:"data"`
@@ -97,166 +141,222 @@ func TwitchInit(session *discordgo.Session, conf *Config, db *DBWorker) *Twitch
 		guilds[g.ID] = &TwitchGuild{g.ID, guildStreams}
 	}
 	fmt.Printf("Loaded [%v] streamers\n", counter)
-	return &Twitch{guilds, db, conf, session}
+	t := &Twitch{Guilds: guilds, DB: db, Conf: conf, Discord: session}
+	t.Notifier = &Notifier{
+		Name:     "Twitch",
+		Interval: twitchInterval,
+		Source:   &twitchSource{t},
+		Detector: &twitchDetector{t},
+		Renderer: &twitchRenderer{t},
+		Store:    &twitchStore{t},
+		Discord:  session,
+		DB:       db,
+	}
+	if conf.Twitch.EventSub.CallbackURL != "" {
+		t.startEventSub()
+	}
+	return t
 }
 
-// Update updates status of streamers and notify
-func (t *Twitch) Update() {
-	var gameResult TwitchGameResult
-	var streamResult TwitchStreamResult
-	var streams = make(map[string]*TwitchStreamData)
-	var games = make(map[string]*TwitchGameData)
-	timeout := time.Duration(time.Duration(1) * time.Second)
-	client := &http.Client{
-		Timeout: time.Duration(timeout),
+// request sends Helix API request and decodes response into result
+func (t *Twitch) request(ctx context.Context, method string, query url.Values, result interface{}) error {
+	body, err := t.do(ctx, "GET", fmt.Sprintf("%v%v?%v", twitchAPIURL, method, query.Encode()), nil)
+	if err != nil {
+		return err
 	}
-	streamQuery := url.Values{}
-	gameQuery := url.Values{}
-	for _, g := range t.Guilds {
-		for _, s := range g.Streams {
-			streamQuery.Add("user_login", s.Login)
+	return json.Unmarshal(body, result)
+}
+
+// do sends Helix API request with app access token, token is renewed once if API rejects it
+func (t *Twitch) do(ctx context.Context, method, addr string, payload []byte) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		token, err := t.token.get(ctx, t.Conf.Twitch)
+		if err != nil {
+			return nil, err
 		}
-	}
-	// Streams
-	tsreq, _ := http.NewRequest("GET", fmt.Sprintf("https://api.twitch.tv/helix/streams?%v", streamQuery.Encode()), nil)
-	tsreq.Header.Add("Client-ID", t.Conf.Twitch.ClientID)
-	tsresp, tserr := client.Do(tsreq)
-	if tserr == nil {
-		jerr := json.NewDecoder(tsresp.Body).Decode(&streamResult)
-		if jerr != nil {
-			t.DB.Log("Twitch", "", "Parsing Twitch API stream error")
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
 		}
-	} else {
-		t.DB.Log("Twitch", "", fmt.Sprintf("Getting Twitch API stream error: %v", tserr.Error()))
-		return
+		req, err := http.NewRequest(method, addr, body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Client-ID", t.Conf.Twitch.ClientID)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := httpclient.For("twitch").Do(ctx, req)
+		if attempt == 0 && httpclient.IsStatus(err, http.StatusUnauthorized) {
+			t.token.invalidate()
+			continue
+		}
+		return resp, err
+	}
+}
+
+// twitchChunks splits values by max count of values in one Helix API request
+func twitchChunks(values []string) [][]string {
+	var result [][]string
+	for len(values) > twitchMaxQuery {
+		result = append(result, values[:twitchMaxQuery])
+		values = values[twitchMaxQuery:]
 	}
-	for i, s := range streamResult.Data {
-		gameQuery.Add("id", s.GameID)
-		streams[s.UserID] = &streamResult.Data[i]
+	if len(values) > 0 {
+		result = append(result, values)
 	}
+	return result
+}
 
-	// Games
-	tgreq, _ := http.NewRequest("GET", fmt.Sprintf("https://api.twitch.tv/helix/games?%v", gameQuery.Encode()), nil)
-	tgreq.Header.Add("Client-ID", t.Conf.Twitch.ClientID)
-	tgresp, tgerr := client.Do(tgreq)
-	if tgerr == nil {
-		jerr := json.NewDecoder(tgresp.Body).Decode(&gameResult)
-		if jerr != nil {
-			t.DB.Log("Twitch", "", "Parsing Twitch API game error")
-			return
+// getStreams returns live streams by user ID, key is "user_login" or "user_id"
+func (t *Twitch) getStreams(ctx context.Context, key string, values []string) (map[string]*TwitchStreamData, error) {
+	var streams = make(map[string]*TwitchStreamData)
+	for _, chunk := range twitchChunks(values) {
+		var result TwitchStreamResult
+		query := url.Values{key: chunk, "first": {fmt.Sprint(twitchMaxQuery)}}
+		if err := t.request(ctx, "streams", query, &result); err != nil {
+			return nil, err
+		}
+		for i, s := range result.Data {
+			streams[s.UserID] = &result.Data[i]
+		}
+	}
+	return streams, nil
+}
+
+// getGames returns games of streams by game ID
+func (t *Twitch) getGames(ctx context.Context, streams map[string]*TwitchStreamData) map[string]*TwitchGameData {
+	var games = make(map[string]*TwitchGameData)
+	var ids []string
+	for _, s := range streams {
+		if _, ok := games[s.GameID]; !ok && s.GameID != "" {
+			games[s.GameID] = nil
+			ids = append(ids, s.GameID)
 		}
-	} else {
-		t.DB.Log("Twitch", "", fmt.Sprintf("Getting Twitch API game error: %v", tgerr.Error()))
-		return
 	}
-	for i, g := range gameResult.Data {
-		games[g.ID] = &gameResult.Data[i]
+	for _, chunk := range twitchChunks(ids) {
+		var result TwitchGameResult
+		if err := t.request(ctx, "games", url.Values{"id": chunk}, &result); err != nil {
+			t.DB.Log("Twitch", "", fmt.Sprintf("Getting Twitch API game error: %v", err.Error()))
+			continue
+		}
+		for i, g := range result.Data {
+			games[g.ID] = &result.Data[i]
+		}
 	}
+	return games
+}
 
+// userStreams returns streams of user in all guilds
+func (t *Twitch) userStreams(userID string) []*TwitchStream {
+	t.Lock()
+	defer t.Unlock()
+	var streams []*TwitchStream
 	for _, g := range t.Guilds {
 		for _, s := range g.Streams {
-			if stream, ok := streams[s.UserID]; ok {
-				if !s.IsOnline {
-					gameName := "Unknown"
-					if _,ok := games[stream.GameID]; ok {
-						gameName = games[stream.GameID].Name
-					}
-					t.Guilds[s.Guild].Streams[s.Login].IsOnline = true
-					t.DB.UpdateStream(s)
-					imgURL := strings.Replace(stream.ThumbnailURL, "{width}", "320", -1)
-					imgURL = strings.Replace(imgURL, "{height}", "180", -1)
-					emb := NewEmbed(stream.Title).
-						URL(fmt.Sprintf("http://www.twitch.tv/%v", s.Login)).
-						Author(s.Name, "", s.ProfileImageURL).
-						Field("Viewers", fmt.Sprintf("%v", stream.Viewers), true).
-						Field("Game", gameName, true).
-						AttachImgURL(imgURL).
-						Color(t.Conf.General.EmbedColor)
-					if s.CustomMessage != "" {
-						emb.Content = s.CustomMessage
-					} else {
-						emb.Content = fmt.Sprintf(t.Conf.GetLocaleLang("twitch_online", stream.Language), s.Name, s.Login)
-					}
-					_, _ = t.Discord.ChannelMessageSendComplex(s.Channel, emb.MessageSend)
-				}
-			} else {
-				if s.IsOnline == true {
-					t.Guilds[s.Guild].Streams[s.Login].IsOnline = false
-					t.DB.UpdateStream(s)
-				}
+			if s.UserID == userID {
+				streams = append(streams, s)
 			}
 		}
 	}
+	return streams
 }
 
-// AddStreamer adds new streamer to list
-func (t *Twitch) AddStreamer(guild, channel, login, message string) (string, error) {
+// GuildStreams returns copies of guild streams sorted by login
+func (t *Twitch) GuildStreams(guild string) []TwitchStream {
+	t.Lock()
+	defer t.Unlock()
+	var streams []TwitchStream
 	if g, ok := t.Guilds[guild]; ok {
-		if g.Streams == nil {
-			t.Guilds[guild].Streams = make(map[string]*TwitchStream)
-		}
 		for _, s := range g.Streams {
-			if s.Guild == guild && s.Login == login {
-				return "", errors.New("streamer already exists")
-			}
-		}
-		timeout := time.Duration(time.Duration(1) * time.Second)
-		client := &http.Client{
-			Timeout: time.Duration(timeout),
+			streams = append(streams, *s)
 		}
-		req, _ := http.NewRequest("GET", fmt.Sprintf("https://api.twitch.tv/helix/users?login=%v", login), nil)
-		req.Header.Add("Client-ID", t.Conf.Twitch.ClientID)
-		resp, err := client.Do(req)
-		var result TwitchUserResult
-		if err == nil {
-			err = json.NewDecoder(resp.Body).Decode(&result)
-			if err != nil {
-				return "", errors.New("parsing streamer error")
-			}
-			if len(result.Data) > 0 {
-				stream := TwitchStream{}
-				stream.Login = login
-				stream.Channel = channel
-				stream.Guild = guild
-				stream.UserID = result.Data[0].ID
-				if result.Data[0].Name == "" {
-					stream.Name = login
-				} else {
-					stream.Name = result.Data[0].Name
-				}
-				stream.ProfileImageURL = result.Data[0].ProfileImgURL
-				stream.CustomMessage = message
-				t.Guilds[guild].Streams[login] = &stream
-				t.DB.AddStream(&stream)
-			} else {
-				return "", errors.New("streamer not found")
-			}
-		} else {
-			return "", errors.New("getting streamer error")
+	}
+	sort.Slice(streams, func(i, j int) bool { return streams[i].Login < streams[j].Login })
+	return streams
+}
+
+// AddStreamer adds new streamer to list
+func (t *Twitch) AddStreamer(ctx context.Context, guild, channel, login, message string) (string, error) {
+	t.Lock()
+	g, ok := t.Guilds[guild]
+	var exists bool
+	if ok {
+		if g.Streams == nil {
+			g.Streams = make(map[string]*TwitchStream)
 		}
-		return result.Data[0].Name, nil
+		_, exists = g.Streams[login]
+	}
+	t.Unlock()
+	if !ok {
+		return "", errors.New("guild not found")
 	}
-	return "", errors.New("guild not found")
+	if exists {
+		return "", errors.New("streamer already exists")
+	}
+
+	var result TwitchUserResult
+	err := t.request(ctx, "users", url.Values{"login": {login}}, &result)
+	if err != nil {
+		return "", errors.New("getting streamer error")
+	}
+	if len(result.Data) == 0 {
+		return "", errors.New("streamer not found")
+	}
+	stream := TwitchStream{}
+	stream.Login = login
+	stream.Channel = channel
+	stream.Guild = guild
+	stream.UserID = result.Data[0].ID
+	if result.Data[0].Name == "" {
+		stream.Name = login
+	} else {
+		stream.Name = result.Data[0].Name
+	}
+	stream.ProfileImageURL = result.Data[0].ProfileImgURL
+	stream.CustomMessage = message
+	stream.IsCustom = message != ""
+	t.Lock()
+	// Streamer may be added by another command while user was requested
+	if _, exists := g.Streams[login]; exists {
+		t.Unlock()
+		return "", errors.New("streamer already exists")
+	}
+	g.Streams[login] = &stream
+	t.Unlock()
+	t.DB.AddStream(&stream)
+	if t.eventSub != nil {
+		go t.subscribe(context.Background(), stream.UserID)
+	}
+	return stream.Name, nil
 }
 
 // RemoveStreamer removes streamer from list
 func (t *Twitch) RemoveStreamer(login, guild string) error {
-	complete := false
+	var removed *TwitchStream
+	t.Lock()
 	if g, ok := t.Guilds[guild]; ok {
 		if g.Streams != nil {
 			if t.Guilds[guild].Streams[login] != nil {
 				if g.Streams[login].Login == login && g.Streams[login].Guild == guild {
-					t.DB.RemoveStream(g.Streams[login])
+					removed = g.Streams[login]
 					delete(t.Guilds[guild].Streams, login)
-					complete = true
 				}
 			}
 		}
 	} else {
+		t.Unlock()
 		return errors.New("guild not found")
 	}
-	if !complete {
+	t.Unlock()
+	if removed == nil {
 		return errors.New("streamer not found")
 	}
+	t.DB.RemoveStream(removed)
+	// Subscriptions are shared by guilds, so remove them only with the last streamer
+	if t.eventSub != nil && len(t.userStreams(removed.UserID)) == 0 {
+		go t.unsubscribe(context.Background(), removed.UserID)
+	}
 	return nil
 }
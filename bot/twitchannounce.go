@@ -0,0 +1,135 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// twitchRenderer makes Twitch announcements
+type twitchRenderer struct {
+	t *Twitch
+}
+
+// Render makes announcement of live stream or summary of ended stream
+func (r *twitchRenderer) Render(ctx context.Context, event NotifyEvent) *NewEmbedStruct {
+	s := event.Subscription.(*TwitchStream)
+	if event.Type == NotifyEnded {
+		return r.t.offlineEmbed(s, r.t.getVOD(ctx, s))
+	}
+	return r.t.onlineEmbed(s, event.Item.Data.(*twitchItem).Stream)
+}
+
+// onlineEmbed makes announcement of live stream
+func (t *Twitch) onlineEmbed(s *TwitchStream, stream *TwitchStreamData) *NewEmbedStruct {
+	imgURL := s.CustomImageURL
+	if imgURL == "" {
+		imgURL = strings.Replace(stream.ThumbnailURL, "{width}", "320", -1)
+		imgURL = strings.Replace(imgURL, "{height}", "180", -1)
+	}
+	emb := NewEmbed(stream.Title).
+		URL(fmt.Sprintf("http://www.twitch.tv/%v", s.Login)).
+		Author(s.Name, "", s.ProfileImageURL).
+		Field("Viewers", fmt.Sprintf("%v", stream.Viewers), true).
+		Field("Game", s.GameName, true).
+		AttachImgURL(imgURL).
+		Color(t.Conf.General.EmbedColor)
+	if s.CustomMessage != "" {
+		emb.Content = s.CustomMessage
+	} else {
+		emb.Content = fmt.Sprintf(t.Conf.GetLocaleLang("twitch_online", stream.Language), s.Name, s.Login)
+	}
+	if s.MentionRole != "" {
+		emb.Content = fmt.Sprintf("<@&%v> %v", s.MentionRole, emb.Content)
+	}
+	return emb
+}
+
+// offlineEmbed makes summary of ended stream, vod can be nil
+func (t *Twitch) offlineEmbed(s *TwitchStream, vod *TwitchVideoData) *NewEmbedStruct {
+	link := fmt.Sprintf("http://www.twitch.tv/%v", s.Login)
+	if vod != nil {
+		link = vod.URL
+	}
+	emb := NewEmbed(s.Title).
+		URL(link).
+		Author(s.Name, "", s.ProfileImageURL).
+		Field("Duration", formatStreamDuration(time.Since(s.StartedAt)), true).
+		Field("Peak viewers", fmt.Sprintf("%v", s.PeakViewers), true).
+		Field("Game", s.GameName, true).
+		Color(t.Conf.General.EmbedColor)
+	if vod != nil {
+		emb.Field("VOD", vod.URL, false)
+	}
+	if s.CustomImageURL != "" {
+		emb.AttachImgURL(s.CustomImageURL)
+	}
+	emb.Content = fmt.Sprintf(t.Conf.GetLocaleLang("twitch_offline", s.Language), s.Name)
+	return emb
+}
+
+// formatStreamDuration returns duration in hours and minutes
+func formatStreamDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// getVOD returns archive video of last stream, nil if streamer does not save broadcasts
+func (t *Twitch) getVOD(ctx context.Context, s *TwitchStream) *TwitchVideoData {
+	var result TwitchVideoResult
+	query := url.Values{"user_id": {s.UserID}, "type": {"archive"}, "first": {"5"}}
+	if err := t.request(ctx, "videos", query, &result); err != nil {
+		t.DB.Log("Twitch", s.Guild, fmt.Sprintf("Getting Twitch API videos error: %v", err.Error()))
+		return nil
+	}
+	for i, v := range result.Data {
+		if v.StreamID == s.StreamID {
+			return &result.Data[i]
+		}
+	}
+	return nil
+}
+
+// SetStreamOption sets announcement option of streamer: role, image or message, "none" resets option
+func (t *Twitch) SetStreamOption(guild, login, option, value string) error {
+	t.Lock()
+	g, ok := t.Guilds[guild]
+	if !ok || g.Streams[login] == nil {
+		t.Unlock()
+		return errors.New("streamer not found")
+	}
+	s := g.Streams[login]
+	if value == "none" {
+		value = ""
+	}
+	switch option {
+	case "role":
+		if value != "" {
+			id, ok := mentionID(value, "@&")
+			if !ok {
+				t.Unlock()
+				return errors.New("wrong role")
+			}
+			value = id
+		}
+		s.MentionRole = value
+	case "image":
+		if value != "" && !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+			t.Unlock()
+			return errors.New("wrong image url")
+		}
+		s.CustomImageURL = value
+	case "message":
+		s.CustomMessage = value
+	default:
+		t.Unlock()
+		return errors.New("unknown option")
+	}
+	s.IsCustom = s.CustomMessage != "" || s.CustomImageURL != "" || s.MentionRole != ""
+	t.Unlock()
+	t.DB.UpdateStreamOptions(s)
+	return nil
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+const twitchTokenURL = "https://id.twitch.tv/oauth2/token"
+
+// twitchToken app access token of client credentials flow
+type twitchToken struct {
+	sync.Mutex
+	value   string
+	expires time.Time
+}
+
+type twitchTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+// get returns app access token, new token is requested if current is missing or expires soon
+func (t *twitchToken) get(ctx context.Context, conf TwitchConfig) (string, error) {
+	t.Lock()
+	defer t.Unlock()
+	if t.value != "" && time.Now().Add(time.Minute).Before(t.expires) {
+		return t.value, nil
+	}
+	if conf.ClientID == "" || conf.ClientSecret == "" {
+		return "", errors.New("twitch client id or secret is not configured")
+	}
+
+	form := url.Values{
+		"client_id":     {conf.ClientID},
+		"client_secret": {conf.ClientSecret},
+		"grant_type":    {"client_credentials"},
+	}
+	body, err := httpclient.For("twitch").Post(ctx, twitchTokenURL, "application/x-www-form-urlencoded", []byte(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	var resp twitchTokenResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", err
+	}
+	if resp.AccessToken == "" {
+		return "", errors.New("twitch token response without token")
+	}
+	t.value = resp.AccessToken
+	t.expires = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	return t.value, nil
+}
+
+// invalidate removes token, next request gets new one
+func (t *twitchToken) invalidate() {
+	t.Lock()
+	t.value = ""
+	t.Unlock()
+}
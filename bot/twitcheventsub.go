@@ -0,0 +1,300 @@
+package bot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	eventSubOnline  = "stream.online"
+	eventSubOffline = "stream.offline"
+	// eventSubMaxAge notifications older than this are rejected as replayed
+	eventSubMaxAge = 10 * time.Minute
+	// eventSubRetries count of attempts to get stream data after online notification,
+	// Helix API shows stream with small delay
+	eventSubRetries = 3
+	// eventSubMinSecret and eventSubMaxSecret allowed length of secret, Twitch rejects subscriptions with other secrets
+	eventSubMinSecret = 10
+	eventSubMaxSecret = 100
+)
+
+// twitchEventSub contains state of EventSub webhook subscriptions
+type twitchEventSub struct {
+	sync.Mutex
+	server *http.Server
+	// subscriptions IDs of subscriptions by broadcaster ID and type
+	subscriptions map[string]map[string]string
+	// seen IDs of handled messages, Twitch may resend notifications
+	seen map[string]time.Time
+}
+
+type eventSubTransport struct {
+	Method   string `json:"method"`
+	Callback string `json:"callback"`
+	Secret   string `json:"secret,omitempty"`
+}
+
+type eventSubCondition struct {
+	BroadcasterUserID string `json:"broadcaster_user_id"`
+}
+
+type eventSubSubscription struct {
+	ID        string            `json:"id,omitempty"`
+	Status    string            `json:"status,omitempty"`
+	Type      string            `json:"type"`
+	Version   string            `json:"version"`
+	Condition eventSubCondition `json:"condition"`
+	Transport eventSubTransport `json:"transport"`
+}
+
+type eventSubList struct {
+	Data       []eventSubSubscription `json:"data"`
+	Pagination struct {
+		Cursor string `json:"cursor"`
+	} `json:"pagination"`
+}
+
+type eventSubMessage struct {
+	Challenge    string               `json:"challenge"`
+	Subscription eventSubSubscription `json:"subscription"`
+	Event        struct {
+		BroadcasterUserID    string `json:"broadcaster_user_id"`
+		BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	} `json:"event"`
+}
+
+// subscribed returns true if both online and offline subscriptions of user exist
+func (e *twitchEventSub) subscribed(userID string) bool {
+	e.Lock()
+	defer e.Unlock()
+	subs := e.subscriptions[userID]
+	return subs[eventSubOnline] != "" && subs[eventSubOffline] != ""
+}
+
+func (e *twitchEventSub) add(sub eventSubSubscription) {
+	e.Lock()
+	defer e.Unlock()
+	id := sub.Condition.BroadcasterUserID
+	if e.subscriptions[id] == nil {
+		e.subscriptions[id] = make(map[string]string)
+	}
+	e.subscriptions[id][sub.Type] = sub.ID
+}
+
+func (e *twitchEventSub) remove(userID, subType string) {
+	e.Lock()
+	defer e.Unlock()
+	delete(e.subscriptions[userID], subType)
+}
+
+// firstSeen remembers message ID and returns false if message was already handled
+func (e *twitchEventSub) firstSeen(id string) bool {
+	e.Lock()
+	defer e.Unlock()
+	for k, t := range e.seen {
+		if time.Since(t) > eventSubMaxAge {
+			delete(e.seen, k)
+		}
+	}
+	if _, ok := e.seen[id]; ok {
+		return false
+	}
+	e.seen[id] = time.Now()
+	return true
+}
+
+// startEventSub starts webhook server and subscribes to tracked streamers
+func (t *Twitch) startEventSub() {
+	if l := len(t.Conf.Twitch.EventSub.Secret); l < eventSubMinSecret || l > eventSubMaxSecret {
+		fmt.Printf("Twitch EventSub is not started: secret must be from %v to %v characters\n", eventSubMinSecret, eventSubMaxSecret)
+		return
+	}
+	callback, err := url.Parse(t.Conf.Twitch.EventSub.CallbackURL)
+	if err != nil {
+		fmt.Printf("Twitch EventSub callback error: %v\n", err)
+		return
+	}
+	path := callback.Path
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, t.handleEventSub)
+	t.eventSub = &twitchEventSub{
+		server:        &http.Server{Addr: t.Conf.Twitch.EventSub.Listen, Handler: mux},
+		subscriptions: make(map[string]map[string]string),
+		seen:          make(map[string]time.Time),
+	}
+	go func() {
+		if err := t.eventSub.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			t.DB.Log("Twitch", "", fmt.Sprintf("EventSub server error: %v", err))
+			fmt.Printf("Twitch EventSub server error: %v\n", err)
+		}
+	}()
+	go t.syncSubscriptions(context.Background())
+}
+
+// syncSubscriptions loads existing subscriptions and creates missing ones
+func (t *Twitch) syncSubscriptions(ctx context.Context) {
+	var cursor string
+	for {
+		query := url.Values{"status": {"enabled"}}
+		if cursor != "" {
+			query.Set("after", cursor)
+		}
+		var list eventSubList
+		if err := t.request(ctx, "eventsub/subscriptions", query, &list); err != nil {
+			t.DB.Log("Twitch", "", fmt.Sprintf("Getting EventSub subscriptions error: %v", err))
+			break
+		}
+		for _, sub := range list.Data {
+			if sub.Transport.Callback == t.Conf.Twitch.EventSub.CallbackURL {
+				t.eventSub.add(sub)
+			}
+		}
+		cursor = list.Pagination.Cursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	var users = make(map[string]bool)
+	t.Lock()
+	for _, g := range t.Guilds {
+		for _, s := range g.Streams {
+			users[s.UserID] = true
+		}
+	}
+	t.Unlock()
+	for id := range users {
+		t.subscribe(ctx, id)
+	}
+}
+
+// subscribe creates missing online and offline subscriptions of user
+func (t *Twitch) subscribe(ctx context.Context, userID string) {
+	for _, subType := range []string{eventSubOnline, eventSubOffline} {
+		t.eventSub.Lock()
+		exists := t.eventSub.subscriptions[userID][subType] != ""
+		t.eventSub.Unlock()
+		if exists {
+			continue
+		}
+		payload, _ := json.Marshal(eventSubSubscription{
+			Type:      subType,
+			Version:   "1",
+			Condition: eventSubCondition{BroadcasterUserID: userID},
+			Transport: eventSubTransport{
+				Method:   "webhook",
+				Callback: t.Conf.Twitch.EventSub.CallbackURL,
+				Secret:   t.Conf.Twitch.EventSub.Secret,
+			},
+		})
+		body, err := t.do(ctx, "POST", twitchAPIURL+"eventsub/subscriptions", payload)
+		if err != nil {
+			t.DB.Log("Twitch", "", fmt.Sprintf("EventSub subscription [%v] error: %v", userID, err))
+			continue
+		}
+		var list eventSubList
+		if err := json.Unmarshal(body, &list); err == nil && len(list.Data) > 0 {
+			t.eventSub.add(list.Data[0])
+		}
+	}
+}
+
+// unsubscribe removes all subscriptions of user
+func (t *Twitch) unsubscribe(ctx context.Context, userID string) {
+	t.eventSub.Lock()
+	subs := t.eventSub.subscriptions[userID]
+	delete(t.eventSub.subscriptions, userID)
+	t.eventSub.Unlock()
+	for _, id := range subs {
+		_, err := t.do(ctx, "DELETE", twitchAPIURL+"eventsub/subscriptions?"+url.Values{"id": {id}}.Encode(), nil)
+		if err != nil {
+			t.DB.Log("Twitch", "", fmt.Sprintf("EventSub unsubscribe [%v] error: %v", userID, err))
+		}
+	}
+}
+
+// verifyEventSub checks message signature made with subscription secret
+func (t *Twitch) verifyEventSub(r *http.Request, body []byte) bool {
+	id := r.Header.Get("Twitch-Eventsub-Message-Id")
+	timestamp := r.Header.Get("Twitch-Eventsub-Message-Timestamp")
+	sent, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil || time.Since(sent) > eventSubMaxAge {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(t.Conf.Twitch.EventSub.Secret))
+	mac.Write([]byte(id + timestamp))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(r.Header.Get("Twitch-Eventsub-Message-Signature")))
+}
+
+// handleEventSub handles webhook requests from Twitch
+func (t *Twitch) handleEventSub(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil || !t.verifyEventSub(r, body) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	var msg eventSubMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch r.Header.Get("Twitch-Eventsub-Message-Type") {
+	case "webhook_callback_verification":
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(msg.Challenge))
+	case "notification":
+		w.WriteHeader(http.StatusNoContent)
+		if !t.eventSub.firstSeen(r.Header.Get("Twitch-Eventsub-Message-Id")) {
+			return
+		}
+		switch msg.Subscription.Type {
+		case eventSubOnline:
+			go t.eventOnline(msg.Event.BroadcasterUserID)
+		case eventSubOffline:
+			// Online streams are polled, so stream that is still shown by Helix API ends on next poll
+			go t.Notifier.CheckSubjects(context.Background(), msg.Event.BroadcasterUserID)
+		}
+	case "revocation":
+		w.WriteHeader(http.StatusNoContent)
+		// Revoked streamers are polled until subscription is created again
+		t.eventSub.remove(msg.Subscription.Condition.BroadcasterUserID, msg.Subscription.Type)
+		t.DB.Log("Twitch", "", fmt.Sprintf("EventSub subscription [%v] revoked: %v",
+			msg.Subscription.Condition.BroadcasterUserID, msg.Subscription.Status))
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// eventOnline checks stream of user until Helix API shows it, notifier sends announcements
+func (t *Twitch) eventOnline(userID string) {
+	for attempt := 0; attempt < eventSubRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(10 * time.Second)
+		}
+		t.Notifier.CheckSubjects(context.Background(), userID)
+		for _, s := range t.userStreams(userID) {
+			t.Lock()
+			online := s.IsOnline
+			t.Unlock()
+			if online {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package bot
+
+import (
+	"context"
+	"time"
+)
+
+// Subject returns Twitch user ID of stream
+func (s *TwitchStream) Subject() string {
+	return s.UserID
+}
+
+// Target returns Discord channel of announcements
+func (s *TwitchStream) Target() string {
+	return s.Channel
+}
+
+// twitchItem contains live stream with name of game
+type twitchItem struct {
+	Stream *TwitchStreamData
+	Game   string
+}
+
+// twitchSource polls live streams from Helix API
+type twitchSource struct {
+	t *Twitch
+}
+
+// Poll returns live streams by user ID, offline users have no items
+func (src *twitchSource) Poll(ctx context.Context, subjects []string) (map[string][]NotifyItem, error) {
+	streams, err := src.t.getStreams(ctx, "user_id", subjects)
+	if err != nil {
+		return nil, err
+	}
+	games := src.t.getGames(ctx, streams)
+	var items = make(map[string][]NotifyItem)
+	for _, id := range subjects {
+		items[id] = nil
+	}
+	for id, stream := range streams {
+		gameName := "Unknown"
+		if game := games[stream.GameID]; game != nil {
+			gameName = game.Name
+		}
+		items[id] = []NotifyItem{{
+			ID:       stream.ID,
+			Live:     true,
+			Revision: stream.Title + "\n" + gameName,
+			Data:     &twitchItem{Stream: stream, Game: gameName},
+		}}
+	}
+	return items, nil
+}
+
+// Watch skips offline streams with EventSub subscriptions, online streams are polled
+// to update viewers, title and game
+func (src *twitchSource) Watch(sub NotifySubscription) bool {
+	s := sub.(*TwitchStream)
+	src.t.Lock()
+	online := s.IsOnline
+	src.t.Unlock()
+	return online || src.t.eventSub == nil || !src.t.eventSub.subscribed(s.UserID)
+}
+
+// twitchDetector tracks broadcast state of streams
+type twitchDetector struct {
+	t *Twitch
+}
+
+// Detect returns start of stream, change of title or game and end of stream
+func (d *twitchDetector) Detect(subject string, subs []NotifySubscription, items []NotifyItem) []NotifyEvent {
+	d.t.Lock()
+	defer d.t.Unlock()
+	var events []NotifyEvent
+	for _, sub := range subs {
+		s := sub.(*TwitchStream)
+		if len(items) == 0 {
+			if s.IsOnline {
+				s.IsOnline = false
+				events = append(events, NotifyEvent{Type: NotifyEnded, Subscription: s, Item: NotifyItem{ID: s.StreamID}, MessageID: s.MessageID})
+			}
+			continue
+		}
+
+		item := items[0]
+		data := item.Data.(*twitchItem)
+		stream := data.Stream
+		if stream.Viewers > s.PeakViewers {
+			s.PeakViewers = stream.Viewers
+		}
+		if !s.IsOnline {
+			s.IsOnline = true
+			s.MessageID = ""
+			s.StreamID = stream.ID
+			s.PeakViewers = stream.Viewers
+			s.StartedAt = time.Now()
+			if startedAt, err := time.Parse(time.RFC3339, stream.StartedAt); err == nil {
+				s.StartedAt = startedAt
+			}
+			s.Title = stream.Title
+			s.GameName = data.Game
+			s.Language = stream.Language
+			events = append(events, NotifyEvent{Type: NotifyStarted, Subscription: s, Item: item})
+			continue
+		}
+		if s.Title+"\n"+s.GameName != item.Revision {
+			s.Title = stream.Title
+			s.GameName = data.Game
+			events = append(events, NotifyEvent{Type: NotifyUpdated, Subscription: s, Item: item, MessageID: s.MessageID})
+		}
+	}
+	return events
+}
+
+// twitchStore keeps streams in memory and MongoDB
+type twitchStore struct {
+	t *Twitch
+}
+
+// Subscriptions returns streams of all guilds
+func (st *twitchStore) Subscriptions() []NotifySubscription {
+	st.t.Lock()
+	defer st.t.Unlock()
+	var subs []NotifySubscription
+	for _, g := range st.t.Guilds {
+		for _, s := range g.Streams {
+			subs = append(subs, s)
+		}
+	}
+	return subs
+}
+
+// Save saves broadcast state of stream
+func (st *twitchStore) Save(event NotifyEvent, messageID string) {
+	s := event.Subscription.(*TwitchStream)
+	st.t.Lock()
+	switch event.Type {
+	case NotifyStarted:
+		s.MessageID = messageID
+	case NotifyEnded:
+		s.MessageID = ""
+	}
+	st.t.Unlock()
+	st.t.DB.UpdateStream(s)
+}
@@ -0,0 +1,33 @@
+package bot
+
+// UserData contains personal settings of user
+type UserData struct {
+	ID string
+	// City is a display name of saved weather location
+	City string
+	Lat  string
+	Lng  string
+}
+
+// HasLocation returns true if user saved weather location
+func (u *UserData) HasLocation() bool {
+	return u != nil && u.Lat != "" && u.Lng != ""
+}
+
+// GetUserData returns settings of current user
+func (ctx *Context) GetUserData() *UserData {
+	data, err := ctx.DB.GetUserData(ctx.User.ID)
+	if err != nil {
+		return &UserData{ID: ctx.User.ID}
+	}
+	return data
+}
+
+// SetUserLocation saves weather location of current user
+func (ctx *Context) SetUserLocation(city, lat, lng string) error {
+	data := ctx.GetUserData()
+	data.City = city
+	data.Lat = lat
+	data.Lng = lng
+	return ctx.DB.SetUserData(data)
+}
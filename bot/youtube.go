@@ -2,11 +2,13 @@ package bot
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"net/http"
 	"net/url"
 	"os/exec"
 	"strings"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 )
 
 const (
@@ -123,16 +125,15 @@ func (youtube Youtube) buildUrl(query string) (*string, error) {
 }
 
 // Search returns array of search results
-func (youtube Youtube) Search(query string) ([]YTSearchContent, error) {
+func (youtube Youtube) Search(ctx context.Context, query string) ([]YTSearchContent, error) {
 	addr, err := youtube.buildUrl(query)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.Get(*addr)
+	var apiResp ytApiResponse
+	err = httpclient.For("youtube").GetJSON(ctx, *addr, &apiResp)
 	if err != nil {
 		return nil, err
 	}
-	var apiResp ytApiResponse
-	_ = json.NewDecoder(resp.Body).Decode(&apiResp)
 	return apiResp.Content, nil
 }
@@ -0,0 +1,393 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	youtubeFeedURL   = "https://www.youtube.com/feeds/videos.xml"
+	youtubeVideosURL = "https://www.googleapis.com/youtube/v3/videos"
+	// youtubeMaxPosts max count of videos announced from one channel per update
+	youtubeMaxPosts = 3
+	// youtubeInterval interval between checks of channel feeds
+	youtubeInterval = 5 * time.Minute
+)
+
+var (
+	youtubeChannelID   = regexp.MustCompile(`^UC[\w-]{22}$`)
+	youtubeChannelPath = regexp.MustCompile(`/channel/(UC[\w-]{22})`)
+	youtubeChannelMeta = regexp.MustCompile(`"(?:channelId|externalId)":"(UC[\w-]{22})"`)
+)
+
+// YoutubeNotify contains subscriptions to YouTube channels
+type YoutubeNotify struct {
+	sync.Mutex
+	Guilds   map[string]*YoutubeNotifyGuild
+	DB       *DBWorker
+	Conf     *Config
+	Discord  *discordgo.Session
+	Notifier *Notifier
+}
+
+// YoutubeNotifyGuild contains YouTube channels from specified guild
+type YoutubeNotifyGuild struct {
+	ID       string
+	Channels map[string]*YoutubeChannel
+}
+
+// YoutubeChannel contains subscription of Discord channel to YouTube channel
+type YoutubeChannel struct {
+	ChannelID     string
+	Name          string
+	Guild         string
+	Channel       string
+	Language      string
+	CustomMessage string
+	// LastVideo ID of last announced video
+	LastVideo     string
+	LastPublished time.Time
+}
+
+// YoutubeFeed contains YouTube channel feed
+type YoutubeFeed struct {
+	Title   string             `xml:"title"`
+	Entries []YoutubeFeedEntry `xml:"entry"`
+}
+
+// YoutubeFeedEntry contains video from YouTube channel feed
+type YoutubeFeedEntry struct {
+	VideoID   string `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+	ChannelID string `xml:"http://www.youtube.com/xml/schemas/2015 channelId"`
+	Title     string `xml:"title"`
+	Link      struct {
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+	Published time.Time `xml:"published"`
+	Media     struct {
+		Thumbnail struct {
+			URL string `xml:"url,attr"`
+		} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+		Description string `xml:"http://search.yahoo.com/mrss/ description"`
+	} `xml:"http://search.yahoo.com/mrss/ group"`
+}
+
+type youtubeVideosResult struct {
+	Items []struct {
+		ID      string `json:"id"`
+		Snippet struct {
+			LiveBroadcastContent string `json:"liveBroadcastContent"`
+		} `json:"snippet"`
+	} `json:"items"`
+}
+
+// YoutubeNotifyInit makes new instance of YouTube notifications worker
+func YoutubeNotifyInit(session *discordgo.Session, conf *Config, db *DBWorker) *YoutubeNotify {
+	guilds := make(map[string]*YoutubeNotifyGuild)
+	var counter int
+	for _, g := range session.State.Guilds {
+		channels := db.GetYoutubeChannels(g.ID)
+		counter += len(channels)
+		guilds[g.ID] = &YoutubeNotifyGuild{g.ID, channels}
+	}
+	fmt.Printf("Loaded [%v] YouTube channels\n", counter)
+	y := &YoutubeNotify{Guilds: guilds, DB: db, Conf: conf, Discord: session}
+	y.Notifier = &Notifier{
+		Name:     "YouTube",
+		Interval: youtubeInterval,
+		Source:   &youtubeSource{y},
+		Detector: &youtubeDetector{y},
+		Renderer: &youtubeRenderer{y},
+		Store:    &youtubeStore{y},
+		Discord:  session,
+		DB:       db,
+	}
+	return y
+}
+
+// GetYoutubeFeed returns latest videos of YouTube channel
+func GetYoutubeFeed(ctx context.Context, channelID string) (*YoutubeFeed, error) {
+	var feed YoutubeFeed
+	err := httpclient.For("youtube").GetXML(ctx, youtubeFeedURL+"?"+url.Values{"channel_id": {channelID}}.Encode(), &feed)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// ResolveYoutubeChannel returns channel ID by channel ID, link or @handle
+func ResolveYoutubeChannel(ctx context.Context, query string) (string, error) {
+	if youtubeChannelID.MatchString(query) {
+		return query, nil
+	}
+	if m := youtubeChannelPath.FindStringSubmatch(query); m != nil {
+		return m[1], nil
+	}
+	page := query
+	if !strings.HasPrefix(page, "http://") && !strings.HasPrefix(page, "https://") {
+		page = "https://www.youtube.com/@" + strings.TrimPrefix(page, "@")
+	} else if !youtubeHost(page) {
+		return "", errors.New("not a YouTube link")
+	}
+	body, err := httpclient.For("youtube").Get(ctx, page)
+	if err != nil {
+		return "", err
+	}
+	if m := youtubeChannelMeta.FindSubmatch(body); m != nil {
+		return string(m[1]), nil
+	}
+	return "", errors.New("channel not found")
+}
+
+// youtubeHost returns true if link leads to YouTube, other links are not requested
+func youtubeHost(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "youtube.com" || strings.HasSuffix(host, ".youtube.com") || host == "youtu.be"
+}
+
+// liveStates returns broadcast state (live, upcoming or none) of videos,
+// states are unknown without YouTube API key
+func (y *YoutubeNotify) liveStates(ctx context.Context, ids []string) map[string]string {
+	var states = make(map[string]string)
+	if y.Conf.YoutubeNotify.APIKey == "" || len(ids) == 0 {
+		return states
+	}
+	query := url.Values{"part": {"snippet"}, "id": {strings.Join(ids, ",")}, "key": {y.Conf.YoutubeNotify.APIKey}}
+	body, err := httpclient.For("youtube").Get(ctx, youtubeVideosURL+"?"+query.Encode())
+	if err != nil {
+		y.DB.Log("YouTube", "", fmt.Sprintf("Getting YouTube API videos error: %v", err))
+		return states
+	}
+	var result youtubeVideosResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return states
+	}
+	for _, v := range result.Items {
+		states[v.ID] = v.Snippet.LiveBroadcastContent
+	}
+	return states
+}
+
+// Subject returns YouTube channel ID
+func (c *YoutubeChannel) Subject() string {
+	return c.ChannelID
+}
+
+// Target returns Discord channel of announcements
+func (c *YoutubeChannel) Target() string {
+	return c.Channel
+}
+
+// youtubeItem contains video with title of channel
+type youtubeItem struct {
+	ChannelTitle string
+	Video        *YoutubeFeedEntry
+}
+
+// youtubeSource polls channel feeds
+type youtubeSource struct {
+	y *YoutubeNotify
+}
+
+// Poll returns videos of channels from newest to oldest, channels with feed errors are skipped
+func (src *youtubeSource) Poll(ctx context.Context, subjects []string) (map[string][]NotifyItem, error) {
+	var items = make(map[string][]NotifyItem)
+	for _, channelID := range subjects {
+		feed, err := GetYoutubeFeed(ctx, channelID)
+		if err != nil {
+			src.y.DB.Log("YouTube", "", fmt.Sprintf("Getting YouTube feed [%v] error: %v", channelID, err))
+			continue
+		}
+		var videos []NotifyItem
+		for i, e := range feed.Entries {
+			videos = append(videos, NotifyItem{ID: e.VideoID, Data: &youtubeItem{ChannelTitle: feed.Title, Video: &feed.Entries[i]}})
+		}
+		items[channelID] = videos
+	}
+	return items, nil
+}
+
+// youtubeDetector finds videos published after last announced video
+type youtubeDetector struct {
+	y *YoutubeNotify
+}
+
+// Detect returns new videos of channel, older videos first
+func (d *youtubeDetector) Detect(subject string, subs []NotifySubscription, items []NotifyItem) []NotifyEvent {
+	d.y.Lock()
+	defer d.y.Unlock()
+	var events []NotifyEvent
+	for _, sub := range subs {
+		c := sub.(*YoutubeChannel)
+		var videos []NotifyItem
+		for _, item := range items {
+			video := item.Data.(*youtubeItem).Video
+			if video.VideoID == c.LastVideo || !video.Published.After(c.LastPublished) {
+				continue
+			}
+			videos = append(videos, item)
+		}
+		if len(videos) == 0 {
+			continue
+		}
+		// Feed lists videos from newest to oldest
+		if len(videos) > youtubeMaxPosts {
+			videos = videos[:youtubeMaxPosts]
+		}
+		latest := videos[0].Data.(*youtubeItem)
+		c.LastVideo = latest.Video.VideoID
+		c.LastPublished = latest.Video.Published
+		if latest.ChannelTitle != "" {
+			c.Name = latest.ChannelTitle
+		}
+		for i := len(videos) - 1; i >= 0; i-- {
+			events = append(events, NotifyEvent{Type: NotifyStarted, Subscription: c, Item: videos[i]})
+		}
+	}
+	return events
+}
+
+// youtubeRenderer makes video announcements
+type youtubeRenderer struct {
+	y *YoutubeNotify
+}
+
+// Render makes announcement of uploaded video, live stream or premiere
+func (r *youtubeRenderer) Render(ctx context.Context, event NotifyEvent) *NewEmbedStruct {
+	item := event.Item.Data.(*youtubeItem)
+	state := r.y.liveStates(ctx, []string{item.Video.VideoID})[item.Video.VideoID]
+	return r.y.videoEmbed(event.Subscription.(*YoutubeChannel), item.ChannelTitle, item.Video, state)
+}
+
+// youtubeStore keeps YouTube channels in memory and MongoDB
+type youtubeStore struct {
+	y *YoutubeNotify
+}
+
+// Subscriptions returns YouTube channels of all guilds
+func (st *youtubeStore) Subscriptions() []NotifySubscription {
+	st.y.Lock()
+	defer st.y.Unlock()
+	var subs []NotifySubscription
+	for _, g := range st.y.Guilds {
+		for _, c := range g.Channels {
+			subs = append(subs, c)
+		}
+	}
+	return subs
+}
+
+// Save saves last announced video of channel
+func (st *youtubeStore) Save(event NotifyEvent, messageID string) {
+	st.y.DB.UpdateYoutubeChannel(event.Subscription.(*YoutubeChannel))
+}
+
+// videoEmbed makes announcement of video
+func (y *YoutubeNotify) videoEmbed(c *YoutubeChannel, channelTitle string, video *YoutubeFeedEntry, state string) *NewEmbedStruct {
+	emb := NewEmbed(video.Title).
+		URL(video.Link.Href).
+		Author(channelTitle, fmt.Sprintf("https://www.youtube.com/channel/%v", c.ChannelID), "").
+		Desc(TruncateText(video.Media.Description, 300)).
+		AttachImgURL(video.Media.Thumbnail.URL).
+		TimeStamp(video.Published.Format(time.RFC3339)).
+		Color(y.Conf.General.EmbedColor)
+	key := "ytnotify_upload"
+	switch state {
+	case "live":
+		key = "ytnotify_live"
+	case "upcoming":
+		key = "ytnotify_upcoming"
+	}
+	if c.CustomMessage != "" {
+		emb.Content = c.CustomMessage
+	} else {
+		emb.Content = fmt.Sprintf(y.Conf.GetLocaleLang(key, c.Language), channelTitle, video.Link.Href)
+	}
+	return emb
+}
+
+// AddChannel adds YouTube channel announcements to Discord channel and returns channel name
+func (y *YoutubeNotify) AddChannel(ctx context.Context, guild, channel, language, query, message string) (string, error) {
+	channelID, err := ResolveYoutubeChannel(ctx, query)
+	if err != nil {
+		return "", errors.New("channel not found")
+	}
+	y.Lock()
+	if _, ok := y.Guilds[guild]; !ok {
+		y.Guilds[guild] = &YoutubeNotifyGuild{ID: guild}
+	}
+	if y.Guilds[guild].Channels == nil {
+		y.Guilds[guild].Channels = make(map[string]*YoutubeChannel)
+	}
+	_, exists := y.Guilds[guild].Channels[channelID]
+	y.Unlock()
+	if exists {
+		return "", errors.New("channel already exists")
+	}
+
+	feed, err := GetYoutubeFeed(ctx, channelID)
+	if err != nil {
+		return "", errors.New("getting channel error")
+	}
+	c := &YoutubeChannel{
+		ChannelID:     channelID,
+		Name:          feed.Title,
+		Guild:         guild,
+		Channel:       channel,
+		Language:      language,
+		CustomMessage: message,
+	}
+	// Videos published before subscription are not announced
+	if len(feed.Entries) > 0 {
+		c.LastVideo = feed.Entries[0].VideoID
+		c.LastPublished = feed.Entries[0].Published
+	}
+	y.Lock()
+	// Channel may be added by another command while feed was requested
+	if _, exists := y.Guilds[guild].Channels[channelID]; exists {
+		y.Unlock()
+		return "", errors.New("channel already exists")
+	}
+	y.Guilds[guild].Channels[channelID] = c
+	y.Unlock()
+	y.DB.AddYoutubeChannel(c)
+	return c.Name, nil
+}
+
+// RemoveChannel removes YouTube channel announcements by channel ID or name
+func (y *YoutubeNotify) RemoveChannel(guild, query string) error {
+	y.Lock()
+	g, ok := y.Guilds[guild]
+	if !ok {
+		y.Unlock()
+		return errors.New("guild not found")
+	}
+	var removed *YoutubeChannel
+	for id, c := range g.Channels {
+		if c.ChannelID == query || strings.EqualFold(c.Name, query) {
+			removed = c
+			delete(g.Channels, id)
+			break
+		}
+	}
+	y.Unlock()
+	if removed == nil {
+		return errors.New("channel not found")
+	}
+	y.DB.RemoveYoutubeChannel(removed)
+	return nil
+}
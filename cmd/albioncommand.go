@@ -1,43 +1,154 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+var (
+	albionKillsArgs = bot.ArgSpec{Command: "!alb kills", Description: "help_alb_kills",
+		Args: []bot.Arg{{Name: "player", Type: bot.ArgString}}}
+	albionKillArgs = bot.ArgSpec{Command: "!alb kill", Description: "help_alb_kill",
+		Args: []bot.Arg{{Name: "kill_id", Type: bot.ArgString}}}
+	albionWatchArgs = bot.ArgSpec{Command: "!alb watch", Description: "help_alb_watch",
+		Args: []bot.Arg{{Name: "player", Type: bot.ArgString}}}
+	albionUnwatchArgs = bot.ArgSpec{Command: "!alb unwatch", Description: "help_alb_unwatch",
+		Args: []bot.Arg{{Name: "player", Type: bot.ArgString, Optional: true}}}
+	albionListArgs   = bot.ArgSpec{Command: "!alb list", Description: "help_alb_list"}
+	albionPlayerArgs = bot.ArgSpec{Command: "!alb player", Description: "help_alb_player",
+		Args: []bot.Arg{{Name: "player", Type: bot.ArgString}}}
+	albionDeathsArgs = bot.ArgSpec{Command: "!alb deaths", Description: "help_alb_deaths",
+		Args: []bot.Arg{{Name: "player", Type: bot.ArgString}}}
+	albionGuildArgs = bot.ArgSpec{Command: "!alb guild", Description: "help_alb_guild",
+		Args: []bot.Arg{{Name: "guild", Type: bot.ArgText}}}
+	albionPriceArgs = bot.ArgSpec{Command: "!alb price", Description: "help_alb_price",
+		Args: []bot.Arg{{Name: "item", Type: bot.ArgText}}}
+	albionGuildWatchArgs = bot.ArgSpec{Command: "!alb guild watch", Description: "help_alb_guild_watch",
+		Args: []bot.Arg{{Name: "guild", Type: bot.ArgText}}}
+	albionGuildUnwatchArgs = bot.ArgSpec{Command: "!alb guild unwatch", Description: "help_alb_guild_unwatch",
+		Args: []bot.Arg{{Name: "guild", Type: bot.ArgText}}}
+	albionAllianceWatchArgs = bot.ArgSpec{Command: "!alb alliance watch", Description: "help_alb_alliance_watch",
+		Args: []bot.Arg{{Name: "alliance", Type: bot.ArgText}}}
+	albionAllianceUnwatchArgs = bot.ArgSpec{Command: "!alb alliance unwatch", Description: "help_alb_alliance_unwatch",
+		Args: []bot.Arg{{Name: "alliance", Type: bot.ArgText}}}
+
+	albionSpecs = []bot.ArgSpec{albionKillsArgs, albionKillArgs, albionPlayerArgs, albionDeathsArgs, albionGuildArgs,
+		albionPriceArgs, albionWatchArgs, albionUnwatchArgs, albionListArgs, albionGuildWatchArgs, albionGuildUnwatchArgs,
+		albionAllianceWatchArgs, albionAllianceUnwatchArgs}
+)
+
+// AlbionInfo metadata of !alb command
+var AlbionInfo = bot.CommandInfo{
+	Category:    "category_games",
+	Description: "cmd_desc_!alb",
+	Specs:       albionSpecs,
+	Examples:    []string{"!alb kills PlayerName", "!alb player PlayerName", "!alb price T4_BAG", "!alb guild watch GuildName"},
+}
+
 // AlbionCommand handle dice
 func AlbionCommand(ctx bot.Context) {
 	if len(ctx.Args) > 0 {
 		switch ctx.Args[0] {
 		case "kills":
-			if len(ctx.Args) > 1 {
+			if args, ok := ctx.ParseArgs(albionKillsArgs, 1); ok {
 				ctx.MetricsCommand("albion", "kills")
-				ctx.AlbionShowKills()
+				ctx.AlbionShowKills(args.String("player"))
 			}
 		case "kill":
-			if len(ctx.Args) > 1 {
+			if args, ok := ctx.ParseArgs(albionKillArgs, 1); ok {
 				ctx.MetricsCommand("albion", "kill")
-				ctx.AlbionShowKill()
+				ctx.AlbionShowKill(args.String("kill_id"))
 			}
 		case "watch":
-			if len(ctx.Args) > 1 {
+			if args, ok := ctx.ParseArgs(albionWatchArgs, 1); ok {
 				ctx.MetricsCommand("albion", "watch")
-				err := ctx.AlbionAddPlayer()
+				name, err := ctx.AlbionAddPlayer(args.String("player"))
 				if err != nil {
-					ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_add_error"))
+					ctx.ReplyEmbed("Albion Killboard", fmt.Sprintf(ctx.Loc("albion_add_error"), err))
 				} else {
-					ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_added"))
+					ctx.ReplyEmbed("Albion Killboard", fmt.Sprintf(ctx.Loc("albion_added"), name))
 				}
 			}
 		case "unwatch":
-			if _,ok := ctx.Albion.Players[ctx.User.ID]; ok {
-				ctx.MetricsCommand("albion", "unwatch")
-				delete(ctx.Albion.Players, ctx.User.ID)
-				ctx.DB.RemoveAlbionPlayer(ctx.User.ID)
-				ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_removed"))
-			} else {
-				ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_not_watching"))
+			if args, ok := ctx.ParseArgs(albionUnwatchArgs, 1); ok {
+				if ctx.AlbionRemovePlayer(args.String("player")) > 0 {
+					ctx.MetricsCommand("albion", "unwatch")
+					ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_removed"))
+				} else {
+					ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_not_watching"))
+				}
+			}
+		case "list":
+			ctx.MetricsCommand("albion", "list")
+			ctx.AlbionShowPlayers()
+		case "player":
+			if args, ok := ctx.ParseArgs(albionPlayerArgs, 1); ok {
+				ctx.MetricsCommand("albion", "player")
+				ctx.AlbionShowPlayer(args.String("player"))
+			}
+		case "deaths":
+			if args, ok := ctx.ParseArgs(albionDeathsArgs, 1); ok {
+				ctx.MetricsCommand("albion", "deaths")
+				ctx.AlbionShowDeaths(args.String("player"))
+			}
+		case "price":
+			if args, ok := ctx.ParseArgs(albionPriceArgs, 1); ok {
+				ctx.MetricsCommand("albion", "price")
+				ctx.AlbionShowPrice(args.String("item"))
 			}
+		case "guild":
+			if len(ctx.Args) > 1 && (ctx.Args[1] == "watch" || ctx.Args[1] == "unwatch") {
+				albionWatchCommand(&ctx)
+			} else if args, ok := ctx.ParseArgs(albionGuildArgs, 1); ok {
+				ctx.MetricsCommand("albion", "guild")
+				ctx.AlbionShowGuild(args.String("guild"))
+			}
+		case "alliance":
+			albionWatchCommand(&ctx)
+		default:
+			ctx.ReplyEmbed("Albion Killboard", ctx.Help(albionSpecs))
 		}
+	} else {
+		ctx.ReplyEmbed("Albion Killboard", ctx.Help(albionSpecs))
 	}
+}
 
+// albionWatchCommand handles watching of guild and alliance killboards
+func albionWatchCommand(ctx *bot.Context) {
+	watchArgs, unwatchArgs := albionGuildWatchArgs, albionGuildUnwatchArgs
+	if ctx.Args[0] == "alliance" {
+		watchArgs, unwatchArgs = albionAllianceWatchArgs, albionAllianceUnwatchArgs
+	}
+	if len(ctx.Args) < 2 {
+		ctx.ReplyEmbed("Albion Killboard", ctx.Help([]bot.ArgSpec{watchArgs, unwatchArgs}))
+		return
+	}
+	if !ctx.IsServerAdmin() {
+		ctx.ReplyEmbed("Albion Killboard", ctx.Loc("admin_require"))
+		return
+	}
+	switch ctx.Args[1] {
+	case "watch":
+		if args, ok := ctx.ParseArgs(watchArgs, 2); ok {
+			ctx.MetricsCommand("albion", ctx.Args[0]+"_watch")
+			name, err := ctx.AlbionAddWatch(ctx.Args[0], args.String(watchArgs.Args[0].Name))
+			if err != nil {
+				ctx.ReplyEmbed("Albion Killboard", fmt.Sprintf(ctx.Loc("albion_watch_error"), err))
+			} else {
+				ctx.ReplyEmbed("Albion Killboard", fmt.Sprintf(ctx.Loc("albion_watch_added"), name))
+			}
+		}
+	case "unwatch":
+		if args, ok := ctx.ParseArgs(unwatchArgs, 2); ok {
+			ctx.MetricsCommand("albion", ctx.Args[0]+"_unwatch")
+			if err := ctx.AlbionRemoveWatch(args.String(unwatchArgs.Args[0].Name)); err != nil {
+				ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_watch_not_found"))
+			} else {
+				ctx.ReplyEmbed("Albion Killboard", ctx.Loc("albion_watch_removed"))
+			}
+		}
+	default:
+		ctx.ReplyEmbed("Albion Killboard", ctx.Help([]bot.ArgSpec{watchArgs, unwatchArgs}))
+	}
 }
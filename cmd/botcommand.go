@@ -3,20 +3,31 @@ package cmd
 import (
 	"fmt"
 	"github.com/bwmarrin/discordgo"
-	"github.com/globalsign/mgo/bson"
 	"strconv"
 	"strings"
 
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
 func showLogs(ctx *bot.Context, count int) {
 	logs := ctx.DB.LogGet(count)
-	var logString = []string{""}
+	var logStrings []string
 	for _, log := range logs {
-		logString = append(logString, fmt.Sprintf("[%v] %v: %v\n", log.Date, log.Module, log.Text))
+		logStrings = append(logStrings, fmt.Sprintf("[%v] %v: %v", log.Date, log.Module, log.Text))
 	}
-	ctx.ReplyEmbedPM("Logs", strings.Join(logString, ""))
+	ctx.SendPagesPM(bot.ListPages("Logs", logStrings, 10))
+}
+
+// BotInfo metadata of !b command
+var BotInfo = bot.CommandInfo{
+	Category:    "category_admin",
+	Description: "cmd_desc_!b",
+	Usage:       "help_command_!b",
+	AdminUsage:  "help_command_!b_admin",
+	AdminSpecs:  botAdminSpecs,
+	Examples:    []string{"!b clear 3", "!b setconf general.language ru"},
+	Permission:  bot.PermissionServerAdmin,
 }
 
 // BotCommand special bot commands handler
@@ -72,14 +83,16 @@ func BotCommand(ctx bot.Context) {
 			ctx.ReplyEmbed("Stats", fmt.Sprintf(ctx.Loc("stats_command"), len(ctx.Discord.State.Guilds), users))
 		case "blacklist":
 			botBlacklist(&ctx)
+		case "cache":
+			botCache(&ctx)
 		}
 	} else {
 		ctx.ReplyEmbed("Bot", ctx.Loc("admin_require"))
 	}
 }
 
-func guildsListID(guilds []*discordgo.Guild, current, pages int) string {
-	var list string
+func guildsListID(guilds []*discordgo.Guild) []string {
+	var list []string
 	for _, g := range guilds {
 		var gName string
 		if len(g.Name) > 20 {
@@ -87,14 +100,13 @@ func guildsListID(guilds []*discordgo.Guild, current, pages int) string {
 		} else {
 			gName = g.Name
 		}
-		list += fmt.Sprintf("[%v] - %v\n", g.ID, gName)
+		list = append(list, fmt.Sprintf("[%v] - %v", g.ID, gName))
 	}
-	list += fmt.Sprintf("Page: %v | %v", current, pages)
 	return list
 }
 
-func guildsListName(guilds []*discordgo.Guild, current, pages int) string {
-	var list string
+func guildsListName(guilds []*discordgo.Guild) []string {
+	var list []string
 	for i, g := range guilds {
 		var gName string
 		if len(g.Name) > 20 {
@@ -102,72 +114,32 @@ func guildsListName(guilds []*discordgo.Guild, current, pages int) string {
 		} else {
 			gName = g.Name
 		}
-		list += fmt.Sprintf("[%v] - %v | U: %v\n", i, gName, len(g.Members))
+		list = append(list, fmt.Sprintf("[%v] - %v | U: %v", i, gName, len(g.Members)))
 	}
-	list += fmt.Sprintf("Page: %v | %v", current, pages)
 	return list
 }
 
 func botSetConf(ctx *bot.Context) {
 	ctx.MetricsCommand("bot", "setconf")
 	if len(ctx.Args) > 2 {
-		target := strings.Split(ctx.Args[1], ".")
-		switch target[0] {
-		case "general":
-			switch target[1] {
-			case "language":
-				ctx.Guilds.Guilds[ctx.Guild.ID].Language = ctx.Args[2]
-				_ = ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"language": ctx.Args[2]}})
-				ctx.ReplyEmbedPM("Config", fmt.Sprintf("Language set to: %v", ctx.Args[2]))
-			case "timezone":
-				tz, err := strconv.Atoi(ctx.Args[1])
-				if err != nil {
-					ctx.ReplyEmbedPM("Settings", "Not a number")
-				}
-				ctx.Guilds.Guilds[ctx.Guild.ID].Timezone = tz
-				_ = ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"timezone": tz}})
-				ctx.ReplyEmbedPM("Config", fmt.Sprintf("Timezone set to: %v", ctx.Args[2]))
-			case "nick":
-				_ = ctx.Discord.GuildMemberNickname(ctx.Guild.ID, "@me", ctx.Args[2])
-				ctx.ReplyEmbedPM("Config", fmt.Sprintf("Nickname changed to %v", ctx.Args[2]))
-			}
-		case "weather":
-			switch target[1] {
-			case "city":
-				ctx.Guilds.Guilds[ctx.Guild.ID].WeatherCity = ctx.Args[2]
-				_ = ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"weathercity": ctx.Args[2]}})
-				ctx.ReplyEmbedPM("Config", fmt.Sprintf("Weather city set to: %v", ctx.Args[2]))
-			}
-		case "news":
-			switch target[1] {
-			case "country":
-				ctx.Guilds.Guilds[ctx.Guild.ID].NewsCounty = ctx.Args[2]
-				_ = ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"weathercountry": ctx.Args[2]}})
-				ctx.ReplyEmbedPM("Config", fmt.Sprintf("News country set to: %v", ctx.Args[2]))
-			}
-		case "embed":
-			switch target[1] {
-			case "color":
-				var color int64
-				var err error
-				if strings.HasPrefix(ctx.Args[2], "#") {
-					color, err = strconv.ParseInt(ctx.Args[2][1:], 16, 32)
-					if err != nil {
-						ctx.Log("Config", ctx.Guild.ID, fmt.Sprintf("error setting parameter %v to value %v: %v", ctx.Args[1], target[2], err.Error()))
-						return
-					}
-				} else {
-					color, err = strconv.ParseInt(ctx.Args[2], 16, 32)
-					if err != nil {
-						ctx.Log("Config", ctx.Guild.ID, fmt.Sprintf("error setting parameter %v to value %v: %v", ctx.Args[1], ctx.Args[2], err.Error()))
-						return
-					}
-				}
-				ctx.Guilds.Guilds[ctx.Guild.ID].EmbedColor = int(color)
-				_ = ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"embedcolor": int(color)}})
-				ctx.ReplyEmbedPM("Config", fmt.Sprintf("Embed color set to: %v", ctx.Args[2]))
+		key := ctx.Args[1]
+		value := strings.Join(ctx.Args[2:], " ")
+		if key == "general.nick" {
+			_ = ctx.Discord.GuildMemberNickname(ctx.Guild.ID, "@me", value)
+			ctx.ReplyEmbedPM("Config", fmt.Sprintf("Nickname changed to %v", value))
+			return
+		}
+		if err := ctx.SetGuildSetting(key, value); err != nil {
+			ctx.Log("Config", ctx.Guild.ID, fmt.Sprintf("error setting parameter %v to value %v: %v", key, value, err.Error()))
+			ctx.ReplyEmbedPM("Config", err.Error())
+			return
+		}
+		if key == bot.SettingVoiceVolume {
+			if sess := ctx.Sessions.GetByGuild(ctx.Guild.ID); sess != nil {
+				sess.SetVolume(ctx.GetGuild().VoiceVolume)
 			}
 		}
+		ctx.ReplyEmbedPM("Config", fmt.Sprintf("%v set to: %v", bot.GuildSettingNames[key], ctx.GetGuild().Setting(key)))
 	}
 }
 
@@ -181,6 +153,9 @@ func botGuild(ctx *bot.Context) {
 		if len(ctx.Args) < 3 {
 			return
 		}
+		if !ctx.Confirm(fmt.Sprintf(ctx.Loc("guild_leave_confirm"), ctx.Args[2])) {
+			return
+		}
 		err := ctx.Discord.GuildLeave(ctx.Args[2])
 		if err != nil {
 			ctx.Log("Guild", ctx.Guild.ID, fmt.Sprintf("error leaving from guild [%v]: %v", ctx.Args[2], err.Error()))
@@ -274,89 +249,100 @@ func botGuild(ctx *bot.Context) {
 		emb.Field(ctx.Loc("guild_owner"), fmt.Sprintf(ctx.Loc("guild_owner_format"), guildOwner, guild.OwnerID), true)
 		emb.Send(ctx)
 	case "list":
-		var selected string
-		var paged = false
-		if len(ctx.Args) > 2 && ctx.Args[2] == "id" {
-			if len(ctx.Args) > 3 {
-				selected = ctx.Args[3]
-				paged = true
-			} else {
-				selected = "1"
-			}
-		} else {
-			if len(ctx.Args) > 2 {
-				selected = ctx.Args[2]
-				paged = true
-			} else {
-				selected = "1"
-			}
-		}
-		// calculates count of pages
 		guilds := ctx.Discord.State.Guilds
-		pages := 1 + int(len(guilds)/20)
-		// paginate
-		var indexTo = 20
-		if paged {
-			page, err := strconv.Atoi(selected)
-			if err == nil {
-				indexTo = page * 20
-				indexFrom := indexTo - 20
-
-				if indexFrom < 0 {
-					indexFrom = 0
-				}
-				if indexTo > len(guilds) {
-					indexTo = len(guilds) - 1
-				}
-				if len(ctx.Args) > 2 && ctx.Args[2] == "id" {
-					ctx.ReplyEmbed("Guilds", guildsListID(guilds[indexFrom:indexTo], page, pages)+fmt.Sprintf("\nFrom: %v\nTo: %v", indexFrom, indexTo))
-				} else {
-					ctx.ReplyEmbed("Guilds", guildsListName(guilds[indexFrom:indexTo], page, pages)+fmt.Sprintf("\nFrom: %v\nTo: %v", indexFrom, indexTo))
-				}
-
-			} else {
-				ctx.ReplyEmbed("Guilds", fmt.Sprintf("Selected: %v\nError: %v", selected, err.Error()))
-			}
-
+		if ctx.Arg(2) == "id" {
+			ctx.SendPages(bot.ListPages("Guilds", guildsListID(guilds), 20))
 		} else {
-			if indexTo > len(guilds) {
-				indexTo = len(guilds) - 1
-			}
-			if len(ctx.Args) > 2 && ctx.Args[2] == "id" {
-				ctx.ReplyEmbed("Guilds", guildsListID(guilds[:indexTo], 1, 1)+fmt.Sprintf("\nTo: %v", indexTo))
-			} else {
-				ctx.ReplyEmbed("Guilds", guildsListName(guilds[:indexTo], 1, 1)+fmt.Sprintf("\nTo: %v", indexTo))
-			}
+			ctx.SendPages(bot.ListPages("Guilds", guildsListName(guilds), 20))
 		}
 	}
 }
 
+var (
+	botStationsAddArgs = bot.ArgSpec{Command: "!b stations add", Description: "help_stations_add",
+		Args: []bot.Arg{
+			{Name: "category", Type: bot.ArgString},
+			{Name: "url", Type: bot.ArgString},
+			{Name: "key", Type: bot.ArgString},
+			{Name: "name", Type: bot.ArgText},
+		}}
+	botStationsRemoveArgs = bot.ArgSpec{Command: "!b stations remove", Description: "help_stations_remove",
+		Args: []bot.Arg{{Name: "key", Type: bot.ArgString}}}
+
+	botStationsSpecs = []bot.ArgSpec{botStationsAddArgs, botStationsRemoveArgs}
+
+	botCacheStatsArgs = bot.ArgSpec{Command: "!b cache stats", Description: "help_cache_stats"}
+	botCacheFlushArgs = bot.ArgSpec{Command: "!b cache flush", Description: "help_cache_flush",
+		Args: []bot.Arg{{Name: "provider", Type: bot.ArgString, Optional: true}}}
+
+	botCacheSpecs = []bot.ArgSpec{botCacheStatsArgs, botCacheFlushArgs}
+
+	botAdminSpecs = []bot.ArgSpec{botStationsAddArgs, botStationsRemoveArgs, botCacheStatsArgs, botCacheFlushArgs}
+)
+
 func botStations(ctx *bot.Context) {
 	ctx.MetricsCommand("bot", "stations")
 	if !ctx.IsAdmin() {
 		return
 	}
-	switch ctx.Args[1] {
+	switch ctx.Arg(1) {
 	case "add":
-		if len(ctx.Args) > 5 {
-			name := strings.Join(ctx.Args[5:], " ")
-			err := ctx.DB.AddRadioStation(name, ctx.Args[3], ctx.Args[4], ctx.Args[2])
-			if err != nil {
-				ctx.ReplyEmbed("Stations", "Adding error")
-			}
-			ctx.ReplyEmbed("Stations", ctx.Loc("stations_added"))
-		} else {
-			ctx.ReplyEmbed("Stations", "Arguments missed")
+		args, ok := ctx.ParseArgs(botStationsAddArgs, 2)
+		if !ok {
+			return
+		}
+		err := ctx.DB.AddRadioStation(args.String("name"), args.String("url"), args.String("key"), args.String("category"))
+		if err != nil {
+			ctx.ReplyEmbed("Stations", "Adding error")
+			return
 		}
+		ctx.ReplyEmbed("Stations", ctx.Loc("stations_added"))
 	case "remove":
-		if len(ctx.Args) > 2 {
-			err := ctx.DB.RemoveRadioStation(ctx.Args[2])
-			if err != nil {
-				ctx.ReplyEmbed("Stations", ctx.Loc("stations_removed"))
-			}
+		args, ok := ctx.ParseArgs(botStationsRemoveArgs, 2)
+		if !ok {
+			return
+		}
+		if err := ctx.DB.RemoveRadioStation(args.String("key")); err != nil {
+			ctx.ReplyEmbed("Stations", ctx.Loc("stations_not_found"))
+			return
+		}
+		ctx.ReplyEmbed("Stations", ctx.Loc("stations_removed"))
+	default:
+		ctx.ReplyEmbed("Stations", ctx.Help(botStationsSpecs))
+	}
+}
+
+func botCache(ctx *bot.Context) {
+	ctx.MetricsCommand("bot", "cache")
+	if !ctx.IsAdmin() {
+		return
+	}
+	c := httpclient.Cache()
+	if c == nil {
+		ctx.ReplyEmbed("Cache", ctx.Loc("cache_disabled"))
+		return
+	}
+	switch ctx.Arg(1) {
+	case "stats":
+		var lines []string
+		for _, s := range c.Stats() {
+			lines = append(lines, fmt.Sprintf(ctx.Loc("cache_stats_line"), s.Provider, s.Entries, s.Hits, s.Misses, s.Evictions))
+		}
+		lines = append(lines, fmt.Sprintf(ctx.Loc("cache_stats_total"), c.Len()))
+		ctx.ReplyEmbed("Cache", strings.Join(lines, "\n"))
+	case "flush":
+		args, ok := ctx.ParseArgs(botCacheFlushArgs, 2)
+		if !ok {
+			return
+		}
+		c.Flush(args.String("provider"))
+		if args.Has("provider") {
+			ctx.ReplyEmbed("Cache", fmt.Sprintf(ctx.Loc("cache_flushed"), args.String("provider")))
 		} else {
-			ctx.ReplyEmbed("Stations", "Arguments missed")
+			ctx.ReplyEmbed("Cache", ctx.Loc("cache_flushed_all"))
 		}
+	default:
+		ctx.ReplyEmbed("Cache", ctx.Help(botCacheSpecs))
 	}
 }
 
@@ -364,6 +350,9 @@ func botBlacklist(ctx *bot.Context) {
 	if len(ctx.Args) > 2 {
 		switch ctx.Args[1] {
 		case "addguild":
+			if !ctx.Confirm(fmt.Sprintf(ctx.Loc("blacklist_guild_confirm"), ctx.Args[2])) {
+				return
+			}
 			ctx.BlacklistAddGuild(ctx.Args[2])
 			ctx.ReplyEmbed("Bot", fmt.Sprintf(ctx.Loc("blacklist_guild_add"), ctx.Args[2]))
 		case "adduser":
@@ -4,10 +4,37 @@ import (
 	"fmt"
 	"github.com/FlameInTheDark/dtbot/bot"
 	"gopkg.in/robfig/cron.v2"
-	"strconv"
 	"strings"
 )
 
+var (
+	cronAddArgs = bot.ArgSpec{Command: "!cron add", Description: "help_cron_add",
+		Args: []bot.Arg{
+			{Name: "sec", Type: bot.ArgString},
+			{Name: "min", Type: bot.ArgString},
+			{Name: "hour", Type: bot.ArgString},
+			{Name: "day", Type: bot.ArgString},
+			{Name: "month", Type: bot.ArgString},
+			{Name: "weekday", Type: bot.ArgString},
+			{Name: "command", Type: bot.ArgString},
+			{Name: "args", Type: bot.ArgText, Optional: true},
+		}}
+	cronRemoveArgs = bot.ArgSpec{Command: "!cron remove", Description: "help_cron_remove",
+		Args: []bot.Arg{{Name: "id", Type: bot.ArgInt}}}
+	cronListArgs = bot.ArgSpec{Command: "!cron list", Description: "help_cron_list"}
+
+	cronSpecs = []bot.ArgSpec{cronAddArgs, cronRemoveArgs, cronListArgs}
+)
+
+// CronInfo metadata of !cron command
+var CronInfo = bot.CommandInfo{
+	Category:    "category_admin",
+	Description: "cmd_desc_!cron",
+	Specs:       cronSpecs,
+	Examples:    []string{"!cron add 0 0 7 * * * !w Chelyabinsk", "!cron list"},
+	Permission:  bot.PermissionServerAdmin,
+}
+
 // CronCommand manipulates cron functions
 func CronCommand(ctx bot.Context) {
 	if ctx.IsServerAdmin() {
@@ -15,53 +42,68 @@ func CronCommand(ctx bot.Context) {
 		switch ctx.Arg(0) {
 		case "add":
 			ctx.MetricsCommand("cron", "add")
-			if len(ctx.Args) > 7 {
-				if ctx.Args[0] != "*" && ctx.Args[1] != "*" && ctx.Args[2] != "*" {
-					if len(ctx.Args) > 7 {
-						if !ctx.Data.CronIsFull(&ctx) {
-							cmd := strings.Join(ctx.Args[1:], " ")
-							cronTime := strings.Join(ctx.Args[1:7], " ")
-							trigger := ctx.Args[7]
-							ctx.Args = ctx.Args[8:]
-							id, _ := ctx.Cron.AddFunc(cronTime, func() {
-								switch trigger {
-								case "!w":
-									WeatherCommand(ctx)
-								case "!c":
-									CurrencyCommand(ctx)
-								case "!p":
-									PollCommand(ctx)
-								case "!v":
-									VoiceCommand(ctx)
-								case "!y":
-									YoutubeCommand(ctx)
-								case "!play":
-									YoutubeShortCommand(ctx)
-								case "!b":
-									BotCommand(ctx)
-								case "!n":
-									NewsCommand(ctx)
-								}
-							})
-							_ = ctx.Data.AddCronJob(&ctx, id, cmd)
-							ctx.ReplyEmbedPM("Cron", fmt.Sprintf("Job added: [%v] [%v]", cmd, id))
-						} else {
-							ctx.ReplyEmbedPM("Cron", "Schedule is full")
-						}
+			args, ok := ctx.ParseArgs(cronAddArgs, 1)
+			if !ok {
+				return
+			}
+			// job can not be executed every second or minute
+			if args.String("sec") == "*" || args.String("min") == "*" {
+				ctx.ReplyEmbedPM("Cron", ctx.Usage(cronAddArgs))
+				return
+			}
+			if ctx.Data.CronIsFull(&ctx) {
+				ctx.ReplyEmbedPM("Cron", "Schedule is full")
+				return
+			}
+			var fields []string
+			for _, name := range []string{"sec", "min", "hour", "day", "month", "weekday"} {
+				fields = append(fields, args.String(name))
+			}
+			cronTime := strings.Join(fields, " ")
+			trigger := args.String("command")
+			cmd := strings.TrimSpace(fmt.Sprintf("%v %v %v", cronTime, trigger, args.String("args")))
+			// quoted arguments of triggered command stay single words
+			ctx.Args = bot.SplitArgs(ctx.Args[1:])[len(fields)+1:]
+			id, err := ctx.Cron.AddFunc(cronTime, func() {
+				// every run gets own copy, so overlapping runs do not share call context
+				c := ctx
+				c.RunCommand(trigger, func(ctx bot.Context) {
+					switch trigger {
+					case "!w":
+						WeatherCommand(ctx)
+					case "!c":
+						CurrencyCommand(ctx)
+					case "!p":
+						PollCommand(ctx)
+					case "!v":
+						VoiceCommand(ctx)
+					case "!y":
+						YoutubeCommand(ctx)
+					case "!play":
+						YoutubeShortCommand(ctx)
+					case "!b":
+						BotCommand(ctx)
+					case "!n":
+						NewsCommand(ctx)
 					}
-				}
+				})
+			})
+			if err != nil {
+				ctx.ReplyEmbedPM("Cron", err.Error())
+				return
 			}
+			_ = ctx.Data.AddCronJob(&ctx, id, cmd)
+			ctx.ReplyEmbedPM("Cron", fmt.Sprintf("Job added: [%v] [%v]", cmd, id))
 		case "remove":
 			ctx.MetricsCommand("cron", "remove")
-			val, err := strconv.Atoi(ctx.Args[1])
-			if err != nil {
-				ctx.ReplyEmbedPM("Cron", err.Error())
+			args, ok := ctx.ParseArgs(cronRemoveArgs, 1)
+			if !ok {
 				return
 			}
-			cErr := ctx.Data.CronRemove(&ctx, cron.EntryID(val))
+			cErr := ctx.Data.CronRemove(&ctx, cron.EntryID(args.Int("id")))
 			if cErr != nil {
-				ctx.ReplyEmbedPM("Cron", "Error adding job")
-				fmt.Println("Error adding job: ", cErr.Error())
+				ctx.ReplyEmbedPM("Cron", "Error removing job")
+				fmt.Println("Error removing job: ", cErr.Error())
 				return
 			}
 			ctx.ReplyEmbedPM("Cron", "Job removed")
@@ -77,6 +119,8 @@ func CronCommand(ctx bot.Context) {
 				reply = append(reply, fmt.Sprintf("[%v] - [%v]", key, val))
 			}
 			ctx.ReplyEmbedPM("Cron", strings.Join(reply, "\n"))
+		default:
+			ctx.ReplyEmbed("Cron", ctx.Help(cronSpecs))
 		}
 	} else {
 		ctx.MetricsCommand("cron", "error")
@@ -2,12 +2,40 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/FlameInTheDark/dtbot/api/currency"
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// CurrencyInfo metadata of !c command
+var CurrencyInfo = bot.CommandInfo{
+	Category:    "category_info",
+	Description: "cmd_desc_!c",
+	Usage:       "help_command_!c",
+	Examples:    []string{"!c USD EUR", "!c conv 100 USD EUR"},
+}
+
 // CurrencyCommand Translate handler
 func CurrencyCommand(ctx bot.Context) {
+	switch strings.ToLower(ctx.Arg(0)) {
+	case "chart":
+		currencyChart(&ctx)
+		return
+	case "alert":
+		ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("currency")), currency.AlertCommand(&ctx))
+		return
+	}
 	ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("currency")), currency.GetCurrency(&ctx))
 }
+
+func currencyChart(ctx *bot.Context) {
+	ctx.MetricsCommand("currency", "chart")
+	buf, err := currency.GetChart(ctx)
+	if err != nil {
+		ctx.Log("Currency", ctx.Guild.ID, fmt.Sprintf("chart error: %v", err.Error()))
+		ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("currency")), ctx.Loc("currency_chart_error"))
+		return
+	}
+	ctx.ReplyFile("chart.png", buf)
+}
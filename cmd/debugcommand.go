@@ -8,6 +8,14 @@ import (
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// DebugInfo metadata of !d command
+var DebugInfo = bot.CommandInfo{
+	Category:    "category_admin",
+	Description: "cmd_desc_!d",
+	Usage:       "help_command_!d",
+	Permission:  bot.PermissionServerAdmin,
+}
+
 // DebugCommand special bot commands handler
 func DebugCommand(ctx bot.Context) {
 	if ctx.IsServerAdmin() {
@@ -48,7 +56,7 @@ func DebugCommand(ctx bot.Context) {
 				ctx.ReplyEmbed("Debug", "Voice connection not found")
 			}
 		case "volume":
-			ctx.ReplyEmbed("Debug", fmt.Sprintf("Voice volume is %.2f", ctx.Guilds.Guilds[ctx.Guild.ID].VoiceVolume))
+			ctx.ReplyEmbed("Debug", fmt.Sprintf("Voice volume is %.2f", ctx.GetGuild().VoiceVolume))
 		}
 	} else {
 		ctx.ReplyEmbedPM("Debug", "Not a Admin")
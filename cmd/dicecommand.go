@@ -8,6 +8,14 @@ import (
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// DiceInfo metadata of !dice command
+var DiceInfo = bot.CommandInfo{
+	Category:    "category_fun",
+	Description: "cmd_desc_!dice",
+	Usage:       "help_command_!dice",
+	Examples:    []string{"!dice", "!dice 20"},
+}
+
 // DiceCommand handle dice
 func DiceCommand(ctx bot.Context) {
 	ctx.MetricsCommand("dice", "main")
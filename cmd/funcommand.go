@@ -6,11 +6,19 @@ import (
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// SlapInfo metadata of !slap command
+var SlapInfo = bot.CommandInfo{
+	Category:    "category_fun",
+	Description: "cmd_desc_!slap",
+	Usage:       "help_command_!slap",
+	Examples:    []string{"!slap @user"},
+}
+
 // SlapCommand returns slap image
 func SlapCommand(ctx bot.Context) {
 	if len(ctx.Args) > 0 {
 		ctx.MetricsCommand("fun", "slap")
-		url, err := fun.GetImageURL("slap")
+		url, err := fun.GetImageURL(ctx.CallContext(), "slap")
 		if err == nil {
 			if len(ctx.Args) > 0 {
 				var userID string
@@ -30,11 +38,19 @@ func SlapCommand(ctx bot.Context) {
 	}
 }
 
+// FUInfo metadata of !fu command
+var FUInfo = bot.CommandInfo{
+	Category:    "category_fun",
+	Description: "cmd_desc_!fu",
+	Usage:       "help_command_!fu",
+	Examples:    []string{"!fu @user"},
+}
+
 // FUCommand returns FU image
 func FUCommand(ctx bot.Context) {
 	if len(ctx.Args) > 0 {
 		ctx.MetricsCommand("fun", "fu")
-		url, err := fun.GetImageURL("fu")
+		url, err := fun.GetImageURL(ctx.CallContext(), "fu")
 		if err == nil {
 			if len(ctx.Args) > 0 {
 				var userID string
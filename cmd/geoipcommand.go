@@ -5,6 +5,14 @@ import (
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// GeoIPInfo metadata of !geoip command
+var GeoIPInfo = bot.CommandInfo{
+	Category:    "category_info",
+	Description: "cmd_desc_!geoip",
+	Usage:       "help_command_!geoip",
+	Examples:    []string{"!geoip 8.8.8.8"},
+}
+
 // GeoIPCommand handle dice
 func GeoIPCommand(ctx bot.Context) {
 	ctx.MetricsCommand("geoip", "main")
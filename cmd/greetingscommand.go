@@ -5,6 +5,15 @@ import (
 	"strings"
 )
 
+// GreetingsInfo metadata of !greetings command
+var GreetingsInfo = bot.CommandInfo{
+	Category:    "category_admin",
+	Description: "cmd_desc_!greetings",
+	Usage:       "help_command_!greetings",
+	Examples:    []string{"!greetings add Welcome to our server!"},
+	Permission:  bot.PermissionServerAdmin,
+}
+
 // GreetingsCommand handle greetings command
 func GreetingsCommand(ctx bot.Context) {
 	if ctx.IsServerAdmin() {
@@ -17,14 +26,23 @@ func GreetingsCommand(ctx bot.Context) {
 					ctx.ReplyEmbed(ctx.Loc("greetings"), ctx.Loc("greetings_add"))
 				} else {
 					ctx.MetricsCommand("greetings", "add_no_text")
-					ctx.ReplyEmbed(ctx.Loc("greetings"), ctx.Loc("greetings_no_text"))
+					text, err := ctx.Prompt(ctx.Loc("greetings_prompt"))
+					if err != nil || text == "" {
+						ctx.ReplyEmbed(ctx.Loc("greetings"), ctx.Loc("greetings_no_text"))
+						return
+					}
+					ctx.AddGreetings(text)
+					ctx.ReplyEmbed(ctx.Loc("greetings"), ctx.Loc("greetings_add"))
 				}
 			case "remove":
 				ctx.MetricsCommand("greetings", "remove")
+				if !ctx.Confirm(ctx.Loc("greetings_remove_confirm")) {
+					return
+				}
 				ctx.RemoveGreetings()
 			case "test":
 				ctx.MetricsCommand("greetings", "test")
-				_ = ctx.ReplyPM(ctx.Guilds.Guilds[ctx.Guild.ID].Greeting)
+				_ = ctx.ReplyPM(ctx.GetGuild().Greeting)
 			}
 		}
 	} else {
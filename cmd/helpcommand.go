@@ -1,46 +1,95 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// helpCategories order of categories in help index
+var helpCategories = []string{"category_info", "category_music", "category_fun", "category_games", "category_notify", "category_admin", "category_help"}
+
+// HelpInfo metadata of !help command
+var HelpInfo = bot.CommandInfo{
+	Category:    "category_help",
+	Description: "cmd_desc_!help",
+	Usage:       "help_command_!help",
+	Examples:    []string{"!help", "!help w"},
+}
+
 // HelpCommand shows help
 func HelpCommand(ctx bot.Context) {
 	ctx.MetricsCommand("help_command", "main")
 	if len(ctx.Args) == 0 {
-		ctx.ReplyEmbed(ctx.Loc("help"), ctx.Loc("help_reply"))
+		helpIndex(&ctx)
 		return
 	}
 
-	commandMap := map[string]string{
-		"v":         "help_command_!v",
-		"b":         "help_command_!b",
-		"y":         "help_command_!y",
-		"r":         "help_command_!r",
-		"w":         "help_command_!w",
-		"n":         "help_command_!n",
-		"t":         "help_command_!t",
-		"c":         "help_command_!c",
-		"p":         "help_command_!p",
-		"geoip":     "help_command_!geoip",
-		"twitch":    "help_command_!twitch",
-		"greetings": "help_command_!greetings",
-		"bot.admin": "admin_help",
+	if ctx.Args[0] == "bot.admin" {
+		ctx.ReplyEmbed(ctx.Loc("help"), ctx.Loc("admin_help"))
+		return
 	}
 
-	adminCommandMap := map[string]string{
-		"b": "help_command_!b_admin",
+	name := ctx.Args[0]
+	if !strings.HasPrefix(name, "!") {
+		name = "!" + name
 	}
+	info, ok := ctx.CmdHandler.Info(name)
+	if !ok || !ctx.HasPermission(info.Permission) {
+		ctx.ReplyEmbed(ctx.Loc("help"), ctx.Loc("help_not_found"))
+		return
+	}
+	helpCommand(&ctx, info)
+}
 
-	if _, ok := commandMap[ctx.Args[0]]; !ok {
-		ctx.ReplyEmbed(ctx.Loc("help"), ctx.Loc("help_reply"))
+// helpIndex shows list of available commands grouped by category
+func helpIndex(ctx *bot.Context) {
+	var categories = make(map[string][]string)
+	for _, info := range ctx.CmdHandler.Infos() {
+		if !ctx.HasPermission(info.Permission) {
+			continue
+		}
+		categories[info.Category] = append(categories[info.Category], fmt.Sprintf("`%v` | %v", info.Name, ctx.Loc(info.Description)))
 	}
 
-	ctx.ReplyEmbed(ctx.Loc("help"), ctx.Loc(commandMap[ctx.Args[0]]))
+	emb := bot.NewEmbed(ctx.Loc("help")).
+		Desc(ctx.Loc("help_reply")).
+		Footer(ctx.Loc("requested_by") + ": " + ctx.User.Username).
+		Color(ctx.GetGuild().EmbedColor)
+	for _, c := range helpCategories {
+		if len(categories[c]) > 0 {
+			emb.Field(ctx.Loc(c), strings.Join(categories[c], "\n"), false)
+		}
+	}
+	emb.Send(ctx)
+}
 
+// helpCommand shows detailed description of command
+func helpCommand(ctx *bot.Context, info *bot.CommandInfo) {
+	var usage []string
+	if info.Usage != "" {
+		usage = append(usage, ctx.Loc(info.Usage))
+	}
+	if len(info.Specs) > 0 {
+		usage = append(usage, ctx.Help(info.Specs))
+	}
 	if ctx.IsAdmin() {
-		if _, ok := adminCommandMap[ctx.Args[0]]; ok {
-			ctx.ReplyEmbed(ctx.Loc("help"), ctx.Loc(adminCommandMap[ctx.Args[0]]))
+		if info.AdminUsage != "" {
+			usage = append(usage, ctx.Loc(info.AdminUsage))
+		}
+		if len(info.AdminSpecs) > 0 {
+			usage = append(usage, ctx.Help(info.AdminSpecs))
 		}
 	}
+
+	emb := bot.NewEmbed(fmt.Sprintf("%v: %v", ctx.Loc("help"), info.Name)).
+		Desc(ctx.Loc(info.Description)).
+		Field(ctx.Loc("help_usage"), strings.Join(usage, "\n"), false).
+		Footer(ctx.Loc("requested_by") + ": " + ctx.User.Username).
+		Color(ctx.GetGuild().EmbedColor)
+	if len(info.Examples) > 0 {
+		emb.Field(ctx.Loc("help_examples"), "`"+strings.Join(info.Examples, "`\n`")+"`", false)
+	}
+	emb.Send(ctx)
 }
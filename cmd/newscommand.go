@@ -1,12 +1,137 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/FlameInTheDark/dtbot/api/news"
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// NewsInfo metadata of !n command
+var NewsInfo = bot.CommandInfo{
+	Category:    "category_info",
+	Description: "cmd_desc_!n",
+	Usage:       "help_command_!n",
+	Examples:    []string{"!n technology", "!n search bitcoin"},
+}
+
 // NewsCommand News handler
 func NewsCommand(ctx bot.Context) {
-	ctx.MetricsCommand("news", "main")
-	_ = news.GetNews(&ctx)
+	switch ctx.Arg(0) {
+	case "subscribe":
+		newsSubscribe(&ctx)
+	case "unsubscribe":
+		newsUnsubscribe(&ctx)
+	case "feeds":
+		newsFeeds(&ctx)
+	case "search":
+		newsSearch(&ctx)
+	case "sources":
+		newsSources(&ctx)
+	case "source":
+		newsSource(&ctx)
+	default:
+		ctx.MetricsCommand("news", "main")
+		if err := news.GetNews(&ctx); err != nil {
+			ctx.ReplyEmbed(ctx.Loc("news"), err.Error())
+		}
+	}
+}
+
+func newsSearch(ctx *bot.Context) {
+	ctx.MetricsCommand("news", "search")
+	if len(ctx.Args) < 2 {
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("news_search_usage"))
+		return
+	}
+	if err := news.Search(ctx, strings.Join(ctx.Args[1:], " ")); err != nil {
+		ctx.ReplyEmbed(ctx.Loc("news"), err.Error())
+	}
+}
+
+func newsSources(ctx *bot.Context) {
+	ctx.MetricsCommand("news", "sources")
+	if err := news.GetSources(ctx); err != nil {
+		ctx.ReplyEmbed(ctx.Loc("news"), err.Error())
+	}
+}
+
+func newsSource(ctx *bot.Context) {
+	ctx.MetricsCommand("news", "source")
+	if !ctx.IsServerAdmin() {
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("admin_require"))
+		return
+	}
+	if len(ctx.Args) < 3 {
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("news_source_usage"))
+		return
+	}
+	var err error
+	source := strings.ToLower(ctx.Args[2])
+	switch ctx.Args[1] {
+	case "allow":
+		err = ctx.AllowNewsSource(source)
+	case "deny":
+		err = ctx.DenyNewsSource(source)
+	case "reset":
+		err = ctx.ResetNewsSource(source)
+	default:
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("news_source_usage"))
+		return
+	}
+	if err != nil {
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("news_source_not_found"))
+		return
+	}
+	guild := ctx.GetGuild()
+	ctx.ReplyEmbed(ctx.Loc("news"), fmt.Sprintf(ctx.Loc("news_source_lists"),
+		strings.Join(guild.NewsSources, ", "), strings.Join(guild.NewsExcluded, ", ")))
+}
+
+func newsSubscribe(ctx *bot.Context) {
+	ctx.MetricsCommand("news", "subscribe")
+	if !ctx.IsServerAdmin() {
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("admin_require"))
+		return
+	}
+	if len(ctx.Args) < 2 {
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("news_subscribe_usage"))
+		return
+	}
+	feed, err := news.Subscribe(ctx, ctx.Args[1])
+	if err != nil {
+		ctx.ReplyEmbed(ctx.Loc("news"), err.Error())
+		return
+	}
+	ctx.ReplyEmbed(ctx.Loc("news"), fmt.Sprintf(ctx.Loc("news_subscribed"), feed.Title))
+}
+
+func newsUnsubscribe(ctx *bot.Context) {
+	ctx.MetricsCommand("news", "unsubscribe")
+	if !ctx.IsServerAdmin() {
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("admin_require"))
+		return
+	}
+	if len(ctx.Args) < 2 {
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("news_subscribe_usage"))
+		return
+	}
+	if err := ctx.DB.RemoveNewsFeed(ctx.TextChannel.ID, ctx.Args[1]); err != nil {
+		ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("news_feed_not_found"))
+		return
+	}
+	ctx.ReplyEmbed(ctx.Loc("news"), ctx.Loc("news_unsubscribed"))
+}
+
+func newsFeeds(ctx *bot.Context) {
+	ctx.MetricsCommand("news", "feeds")
+	var list string
+	for _, f := range ctx.DB.GetNewsFeeds(ctx.Guild.ID) {
+		list += fmt.Sprintf("<#%v> [%v](%v)\n", f.Channel, f.Title, f.URL)
+	}
+	if list == "" {
+		list = ctx.Loc("news_feeds_empty")
+	}
+	ctx.ReplyEmbed(ctx.Loc("news"), list)
 }
@@ -2,10 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// RadioInfo metadata of !r command
+var RadioInfo = bot.CommandInfo{
+	Category:    "category_music",
+	Description: "cmd_desc_!r",
+	Usage:       "help_command_!r",
+	Examples:    []string{"!r genres", "!r station rr"},
+}
+
 // RadioCommand Player handler
 func RadioCommand(ctx bot.Context) {
 	sess := ctx.Sessions.GetByGuild(ctx.Guild.ID)
@@ -41,11 +50,11 @@ func playerPlay(sess *bot.Session, ctx *bot.Context) {
 	if ctx.Arg(1) == "attachment" {
 		go sess.Player.Start(sess, ctx.Message.Attachments[0].URL, func(msg string) {
 			ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("player")), msg)
-		}, ctx.Guilds.Guilds[ctx.Guild.ID].VoiceVolume)
+		}, ctx.GetGuild().VoiceVolume)
 	} else if len(ctx.Args) > 1 {
 		go sess.Player.Start(sess, ctx.Args[1], func(msg string) {
 			ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("player")), msg)
-		}, ctx.Guilds.Guilds[ctx.Guild.ID].VoiceVolume)
+		}, ctx.GetGuild().VoiceVolume)
 	}
 }
 
@@ -65,21 +74,25 @@ func playerList(ctx *bot.Context) {
 	}
 
 	if len(stations) > 0 {
-		var embed = bot.NewEmbed(ctx.Loc("player"))
-		for c, st := range category {
-			var response string
-			if len(st) > 20 {
-				for _, s := range st[:20] {
-					response += fmt.Sprintf("[%v] - %v\n", s.Key, s.Name)
-				}
-			} else {
-				for _, s := range st {
-					response += fmt.Sprintf("[%v] - %v\n", s.Key, s.Name)
+		var categories []string
+		for c := range category {
+			categories = append(categories, c)
+		}
+		sort.Strings(categories)
+
+		// every page contains up to 20 stations of one category
+		var pages []*bot.NewEmbedStruct
+		for _, c := range categories {
+			st := category[c]
+			for i := 0; i < len(st); i += 20 {
+				var response string
+				for j := i; j < i+20 && j < len(st); j++ {
+					response += fmt.Sprintf("[%v] - %v\n", st[j].Key, st[j].Name)
 				}
+				pages = append(pages, bot.NewEmbed(ctx.Loc("player")).Field(c, response, false))
 			}
-			embed.Field(c, response, false).Color(ctx.GuildConf().EmbedColor)
 		}
-		embed.Send(ctx)
+		ctx.SendPages(pages)
 	} else {
 		ctx.ReplyEmbed(ctx.Loc("player"), ctx.Loc("stations_not_found"))
 	}
@@ -114,6 +127,6 @@ func playerStation(sess *bot.Session, ctx *bot.Context) {
 		}
 		go sess.Player.Start(sess, station.URL, func(msg string) {
 			ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("player")), msg)
-		}, ctx.Guilds.Guilds[ctx.Guild.ID].VoiceVolume)
+		}, ctx.GetGuild().VoiceVolume)
 	}
 }
@@ -8,6 +8,14 @@ import (
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// PollInfo metadata of !p command
+var PollInfo = bot.CommandInfo{
+	Category:    "category_fun",
+	Description: "cmd_desc_!p",
+	Usage:       "help_command_!p",
+	Examples:    []string{"!p new yes|no", "!p vote 1"},
+}
+
 // PollCommand handle polls commands
 func PollCommand(ctx bot.Context) {
 	if len(ctx.Args) == 0 {
@@ -2,13 +2,30 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/FlameInTheDark/dtbot/api/translate"
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// TranslateInfo metadata of !t command
+var TranslateInfo = bot.CommandInfo{
+	Category:    "category_info",
+	Description: "cmd_desc_!t",
+	Usage:       "help_command_!t",
+	Examples:    []string{"!t ru Hello world", "!t en-ru Hello world"},
+}
+
 // TranslateCommand Translate handler
 func TranslateCommand(ctx bot.Context) {
+	switch ctx.Arg(0) {
+	case "auto":
+		translateAuto(&ctx)
+		return
+	case "reactions":
+		translateReactions(&ctx)
+		return
+	}
 	ctx.MetricsCommand("translate", "main")
 	resp, err := translate.GetTranslation(&ctx)
 	if err != nil {
@@ -21,3 +38,84 @@ func TranslateCommand(ctx bot.Context) {
 		ctx.ReplyEmbed(fmt.Sprintf("%v: ", ctx.Loc("translate")), resp)
 	}
 }
+
+// channelID returns channel id from channel mention
+func channelID(mention string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(mention, "<#"), ">")
+}
+
+// translateAuto manages automatic translation of current channel
+func translateAuto(ctx *bot.Context) {
+	if !ctx.IsServerAdmin() {
+		ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("admin_require"))
+		return
+	}
+	switch ctx.Arg(1) {
+	case "add":
+		ctx.MetricsCommand("translate", "auto_add")
+		_, lang, ok := translate.ParseLanguages(ctx.Arg(3))
+		if len(ctx.Args) < 4 || !ok {
+			ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("translate_auto_usage"))
+			return
+		}
+		target := channelID(ctx.Args[2])
+		// Translations are sent only to channels of the same guild
+		if ch, err := ctx.Discord.State.Channel(target); err != nil || ch.GuildID != ctx.Guild.ID || target == ctx.TextChannel.ID {
+			ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("translate_auto_usage"))
+			return
+		}
+		if err := ctx.AddAutoTranslate(ctx.TextChannel.ID, target, lang); err != nil {
+			ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("translate_auto_exists"))
+			return
+		}
+		ctx.ReplyEmbed(ctx.Loc("translate"), fmt.Sprintf(ctx.Loc("translate_auto_added"), target, lang))
+	case "remove":
+		ctx.MetricsCommand("translate", "auto_remove")
+		if len(ctx.Args) < 3 {
+			ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("translate_auto_usage"))
+			return
+		}
+		if err := ctx.RemoveAutoTranslate(ctx.TextChannel.ID, channelID(ctx.Args[2])); err != nil {
+			ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("translate_auto_not_found"))
+			return
+		}
+		ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("translate_auto_removed"))
+	default:
+		ctx.MetricsCommand("translate", "auto_list")
+		var list string
+		for _, r := range ctx.GetGuild().AutoTranslate {
+			list += fmt.Sprintf("<#%v> → <#%v> (%v)\n", r.Source, r.Target, r.Language)
+		}
+		if list == "" {
+			list = ctx.Loc("translate_auto_empty")
+		}
+		ctx.ReplyEmbed(ctx.Loc("translate"), list)
+	}
+}
+
+// translateReactions enables or disables translation by flag reactions
+func translateReactions(ctx *bot.Context) {
+	if !ctx.IsServerAdmin() {
+		ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("admin_require"))
+		return
+	}
+	ctx.MetricsCommand("translate", "reactions")
+	var enabled bool
+	switch ctx.Arg(1) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("translate_reactions_usage"))
+		return
+	}
+	if err := ctx.SetReactionTranslate(enabled); err != nil {
+		ctx.Log("Translate", ctx.Guild.ID, fmt.Sprintf("error saving reactions setting: %v", err.Error()))
+	}
+	if enabled {
+		ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("translate_reactions_on"))
+	} else {
+		ctx.ReplyEmbed(ctx.Loc("translate"), ctx.Loc("translate_reactions_off"))
+	}
+}
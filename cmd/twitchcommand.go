@@ -6,6 +6,22 @@ import (
 	"strings"
 )
 
+// TwitchInfo metadata of !twitch command
+var TwitchInfo = bot.CommandInfo{
+	Category:    "category_notify",
+	Description: "cmd_desc_!twitch",
+	Usage:       "help_command_!twitch",
+	Specs:       []bot.ArgSpec{twitchSetArgs},
+	Examples:    []string{"!twitch add shroud", "!twitch set shroud role @Streams", "!twitch set shroud image none"},
+}
+
+var twitchSetArgs = bot.ArgSpec{Command: "!twitch set", Description: "help_twitch_set",
+	Args: []bot.Arg{
+		{Name: "login", Type: bot.ArgString},
+		{Name: "option", Type: bot.ArgString},
+		{Name: "value", Type: bot.ArgText},
+	}}
+
 // TwitchCommand manipulates twitch announcer
 func TwitchCommand(ctx bot.Context) {
 	if ctx.IsServerAdmin() {
@@ -19,6 +35,8 @@ func TwitchCommand(ctx bot.Context) {
 			twitchRemove(&ctx)
 		case "list":
 			twitchList(&ctx)
+		case "set":
+			twitchSet(&ctx)
 		case "count":
 			twitchCount(&ctx)
 		}
@@ -31,14 +49,14 @@ func TwitchCommand(ctx bot.Context) {
 func twitchAdd(ctx *bot.Context) {
 	ctx.MetricsCommand("twitch", "add")
 	if len(ctx.Args) > 2 {
-		username, err := ctx.Twitch.AddStreamer(ctx.Guild.ID, ctx.Message.ChannelID, ctx.Args[1], strings.Join(ctx.Args[2:], " "))
+		username, err := ctx.Twitch.AddStreamer(ctx.CallContext(), ctx.Guild.ID, ctx.Message.ChannelID, ctx.Args[1], strings.Join(ctx.Args[2:], " "))
 		if err != nil {
 			ctx.ReplyEmbed("Twitch", ctx.Loc("twitch_add_error"))
 		} else {
 			ctx.ReplyEmbed("Twitch", fmt.Sprintf(ctx.Loc("twitch_added"), username))
 		}
 	} else if len(ctx.Args) > 1 {
-		username, err := ctx.Twitch.AddStreamer(ctx.Guild.ID, ctx.Message.ChannelID, ctx.Args[1], "")
+		username, err := ctx.Twitch.AddStreamer(ctx.CallContext(), ctx.Guild.ID, ctx.Message.ChannelID, ctx.Args[1], "")
 		if err != nil {
 			ctx.ReplyEmbed("Twitch", ctx.Loc("twitch_add_error"))
 		} else {
@@ -59,28 +77,32 @@ func twitchRemove(ctx *bot.Context) {
 	}
 }
 
+func twitchSet(ctx *bot.Context) {
+	ctx.MetricsCommand("twitch", "set")
+	args, ok := ctx.ParseArgs(twitchSetArgs, 1)
+	if !ok {
+		return
+	}
+	err := ctx.Twitch.SetStreamOption(ctx.Guild.ID, args.String("login"), args.String("option"), args.String("value"))
+	if err != nil {
+		ctx.ReplyEmbed("Twitch", fmt.Sprintf(ctx.Loc("twitch_set_error"), err.Error()))
+		return
+	}
+	ctx.ReplyEmbed("Twitch", ctx.Loc("twitch_set"))
+}
+
 func twitchList(ctx *bot.Context) {
 	ctx.MetricsCommand("twitch", "list")
-	if g, ok := ctx.Twitch.Guilds[ctx.Guild.ID]; ok {
-		if len(g.Streams) > 0 {
-			list := ""
-			var counter int
-			if g.Streams != nil {
-				for _, s := range g.Streams {
-					list += fmt.Sprintf("%v. %v\n", counter, s.Login)
-					counter++
-				}
-				ctx.ReplyEmbed("Twitch", fmt.Sprintf(ctx.Loc("twitch_list"), list))
-			} else {
-				ctx.ReplyEmbed("Twitch", ctx.Loc("twitch_list_empty"))
-			}
-
-		} else {
-			ctx.ReplyEmbed("Twitch", ctx.Loc("twitch_list_empty"))
-		}
-	} else {
+	g, ok := ctx.Twitch.Guilds[ctx.Guild.ID]
+	if !ok || len(g.Streams) == 0 {
 		ctx.ReplyEmbed("Twitch", ctx.Loc("twitch_list_empty"))
+		return
+	}
+	var list []string
+	for _, s := range g.Streams {
+		list = append(list, fmt.Sprintf("%v. %v", len(list), s.Login))
 	}
+	ctx.SendPages(bot.ListPages(ctx.Loc("twitch_list"), list, 20))
 }
 
 func twitchCount(ctx *bot.Context) {
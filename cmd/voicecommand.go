@@ -3,10 +3,16 @@ package cmd
 import (
 	"fmt"
 	"github.com/FlameInTheDark/dtbot/bot"
-	"github.com/globalsign/mgo/bson"
-	"strconv"
 )
 
+// VoiceInfo metadata of !v command
+var VoiceInfo = bot.CommandInfo{
+	Category:    "category_music",
+	Description: "cmd_desc_!v",
+	Usage:       "help_command_!v",
+	Examples:    []string{"!v join"},
+}
+
 // VoiceCommand voice handler
 func VoiceCommand(ctx bot.Context) {
 	sess := ctx.Sessions.GetByGuild(ctx.Guild.ID)
@@ -37,7 +43,7 @@ func voiceJoin(sess *bot.Session, ctx *bot.Context) {
 	sess, err := ctx.Sessions.Join(ctx.Discord, ctx.Guild.ID, vc.ID, bot.JoinProperties{
 		Muted:    false,
 		Deafened: true,
-	}, ctx.Guilds.Guilds[ctx.Guild.ID].VoiceVolume)
+	}, ctx.GetGuild().VoiceVolume)
 	if err != nil {
 		ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("player")), ctx.Loc("player_error"))
 		return
@@ -51,23 +57,20 @@ func voiceLeave(sess *bot.Session, ctx *bot.Context) {
 		ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("player")), ctx.Loc("player_must_be_in_voice"))
 		return
 	}
-	ctx.Sessions.Leave(ctx.Discord, *sess)
+	ctx.Sessions.Leave(ctx.Discord, sess)
 	ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("player")), fmt.Sprintf("%v <#%v>!", ctx.Loc("player_left"), sess.ChannelID))
 }
 
 func voiceVolume(sess *bot.Session, ctx *bot.Context) {
 	if len(ctx.Args) > 1 {
-		vol, err := strconv.ParseFloat(ctx.Args[1], 32)
-		if err != nil {
+		if err := ctx.SetGuildSetting(bot.SettingVoiceVolume, ctx.Args[1]); err != nil {
 			ctx.ReplyEmbed(ctx.Loc("player"), ctx.Loc("player_wrong_volume"))
 			return
 		}
-		ctx.Guilds.Guilds[ctx.Guild.ID].VoiceVolume = float32(vol * 0.01)
-		_ = ctx.DB.Guilds().Update(bson.M{"id": ctx.Guild.ID}, bson.M{"$set": bson.M{"voicevolume": float32(vol * 0.01)}})
 		ctx.ReplyEmbed(ctx.Loc("player"), fmt.Sprintf(ctx.Loc("player_volume_changed"), ctx.Args[1]))
 		sess := ctx.Sessions.GetByGuild(ctx.Guild.ID)
 		if sess != nil {
-			sess.Volume = float32(vol * 0.01)
+			sess.SetVolume(ctx.GetGuild().VoiceVolume)
 		}
 	}
 }
@@ -1,17 +1,61 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/FlameInTheDark/dtbot/api/darksky"
+	"github.com/FlameInTheDark/dtbot/api/geocoding"
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// WeatherInfo metadata of !w command
+var WeatherInfo = bot.CommandInfo{
+	Category:    "category_info",
+	Description: "cmd_desc_!w",
+	Usage:       "help_command_!w",
+	Examples:    []string{"!w New York", "!w set London"},
+}
+
 // WeatherCommand weather handler
 func WeatherCommand(ctx bot.Context) {
+	if ctx.Arg(0) == "set" {
+		weatherSetLocation(&ctx)
+		return
+	}
 	ctx.MetricsCommand("weather", "main")
 	buf, err := darksky.GetWeatherImage(&ctx)
 	if err != nil {
+		if geocoding.IsNotFound(err) {
+			ctx.ReplyEmbed(ctx.Loc("weather"), ctx.Loc("location_404"))
+			return
+		}
 		ctx.Log("Weather", ctx.Guild.ID, err.Error())
 		return
 	}
 	ctx.ReplyFile("weather.png", buf)
 }
+
+// weatherSetLocation saves personal weather location of user
+func weatherSetLocation(ctx *bot.Context) {
+	ctx.MetricsCommand("weather", "set")
+	if len(ctx.Args) < 2 {
+		ctx.ReplyEmbed(ctx.Loc("weather"), ctx.Loc("weather_set_usage"))
+		return
+	}
+	loc, err := ctx.Geocode(strings.Join(ctx.Args[1:], " "))
+	if err != nil {
+		if !geocoding.IsNotFound(err) {
+			ctx.Log("Weather", ctx.Guild.ID, fmt.Sprintf("error resolving location: %v", err.Error()))
+		}
+		ctx.ReplyEmbed(ctx.Loc("weather"), ctx.Loc("location_404"))
+		return
+	}
+	err = ctx.SetUserLocation(loc.Name, loc.Lat, loc.Lng)
+	if err != nil {
+		ctx.Log("Weather", ctx.Guild.ID, fmt.Sprintf("error saving user location: %v", err.Error()))
+		ctx.ReplyEmbed(ctx.Loc("weather"), ctx.Loc("weather_error"))
+		return
+	}
+	ctx.ReplyEmbed(ctx.Loc("weather"), fmt.Sprintf(ctx.Loc("weather_location_set"), loc.Name))
+}
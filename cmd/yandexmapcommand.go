@@ -1,15 +1,28 @@
 package cmd
 
 import (
+	"github.com/FlameInTheDark/dtbot/api/geocoding"
 	"github.com/FlameInTheDark/dtbot/api/yandexmap"
 	"github.com/FlameInTheDark/dtbot/bot"
 )
 
+// YandexmapInfo metadata of !m command
+var YandexmapInfo = bot.CommandInfo{
+	Category:    "category_info",
+	Description: "cmd_desc_!m",
+	Usage:       "help_command_!m",
+	Examples:    []string{"!m Moscow"},
+}
+
 // YandexmapCommand returns map image from Yandex API
 func YandexmapCommand(ctx bot.Context) {
 	ctx.MetricsCommand("yandexmap", "map")
 	buf, err := yandexmap.GetMapImage(&ctx)
 	if err != nil {
+		if geocoding.IsNotFound(err) {
+			ctx.ReplyEmbed("Map", ctx.Loc("location_404"))
+			return
+		}
 		ctx.Log("Map", ctx.Guild.ID, err.Error())
 		return
 	}
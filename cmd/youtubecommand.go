@@ -4,9 +4,16 @@ import (
 	"fmt"
 	"github.com/FlameInTheDark/dtbot/bot"
 	"github.com/bwmarrin/discordgo"
-	"strings"
 )
 
+// YoutubeInfo metadata of !y command
+var YoutubeInfo = bot.CommandInfo{
+	Category:    "category_music",
+	Description: "cmd_desc_!y",
+	Usage:       "help_command_!y",
+	Examples:    []string{"!y add https://www.youtube.com/watch?v=dQw4w9WgXcQ", "!y play"},
+}
+
 // YoutubeCommand youtube handler
 func YoutubeCommand(ctx bot.Context) {
 	sess := ctx.Sessions.GetByGuild(ctx.Guild.ID)
@@ -41,7 +48,7 @@ func youtubePlay(sess *bot.Session, ctx *bot.Context) {
 		nsess, serr := ctx.Sessions.Join(ctx.Discord, ctx.Guild.ID, vc.ID, bot.JoinProperties{
 			Muted:    false,
 			Deafened: true,
-		}, ctx.Guilds.Guilds[ctx.Guild.ID].VoiceVolume)
+		}, ctx.GetGuild().VoiceVolume)
 		if serr != nil {
 			//ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("player")), ctx.Loc("player_error") + " : " + serr.Error())
 			ctx.Log("Youtube", ctx.Guild.ID, fmt.Sprintf("player error: %v", serr.Error()))
@@ -162,20 +169,10 @@ func youtubeList(sess *bot.Session, ctx *bot.Context) {
 		return
 	}
 	var songsNames []string
-	var count = 0
-	var countMore = 0
-	for _, val := range sess.Queue.Get() {
-		count++
-		if count <= 10 {
-			songsNames = append(songsNames, fmt.Sprintf("[%v]: %v", count, val.Title))
-		} else {
-			countMore++
-		}
-	}
-	if countMore > 0 {
-		songsNames = append(songsNames, fmt.Sprintf(ctx.Loc("youtube_list_more_format"), countMore))
+	for i, val := range sess.Queue.Get() {
+		songsNames = append(songsNames, fmt.Sprintf("[%v]: %v", i+1, val.Title))
 	}
-	ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("youtube")), fmt.Sprintf(ctx.Loc("youtube_list_format"), strings.Join(songsNames, "\n")))
+	ctx.SendPages(bot.ListPages(ctx.Loc("youtube_list_title"), songsNames, 10))
 }
 
 func youtubeClear(sess *bot.Session, ctx *bot.Context) {
@@ -188,6 +185,9 @@ func youtubeClear(sess *bot.Session, ctx *bot.Context) {
 		ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("youtube")), ctx.Loc("youtube_queue_is_empty"))
 		return
 	}
+	if !ctx.Confirm(ctx.Loc("youtube_clear_confirm")) {
+		return
+	}
 	sess.Queue.Clear()
 	ctx.ReplyEmbed(fmt.Sprintf("%v:", ctx.Loc("youtube")), ctx.Loc("youtube_queue_cleared"))
 }
@@ -212,6 +212,14 @@ func shortPlay(ctx *bot.Context, sess *bot.Session, msg *discordgo.Message) (isP
 	return false
 }
 
+// YoutubeShortInfo metadata of !play command
+var YoutubeShortInfo = bot.CommandInfo{
+	Category:    "category_music",
+	Description: "cmd_desc_!play",
+	Usage:       "help_command_!play",
+	Examples:    []string{"!play https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+}
+
 // YoutubeShortCommand handle short command for playing song from youtube
 func YoutubeShortCommand(ctx bot.Context) {
 	ctx.MetricsCommand("youtube_command", "short")
@@ -230,7 +238,7 @@ func YoutubeShortCommand(ctx bot.Context) {
 		nsess, serr := ctx.Sessions.Join(ctx.Discord, ctx.Guild.ID, vc.ID, bot.JoinProperties{
 			Muted:    false,
 			Deafened: true,
-		}, ctx.Guilds.Guilds[ctx.Guild.ID].VoiceVolume)
+		}, ctx.GetGuild().VoiceVolume)
 		sess = nsess
 		if serr != nil {
 			ctx.Log("Youtube", ctx.Guild.ID, fmt.Sprintf("session error: %v", serr.Error()))
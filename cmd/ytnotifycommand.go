@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/FlameInTheDark/dtbot/bot"
+)
+
+var (
+	ytNotifyAddArgs = bot.ArgSpec{Command: "!ytnotify add", Description: "help_ytnotify_add",
+		Args: []bot.Arg{
+			{Name: "channel", Type: bot.ArgString},
+			{Name: "message", Type: bot.ArgText, Optional: true},
+		}}
+	ytNotifyRemoveArgs = bot.ArgSpec{Command: "!ytnotify remove", Description: "help_ytnotify_remove",
+		Args: []bot.Arg{{Name: "channel", Type: bot.ArgText}}}
+	ytNotifyListArgs = bot.ArgSpec{Command: "!ytnotify list", Description: "help_ytnotify_list"}
+
+	ytNotifySpecs = []bot.ArgSpec{ytNotifyAddArgs, ytNotifyRemoveArgs, ytNotifyListArgs}
+)
+
+// YTNotifyInfo metadata of !ytnotify command
+var YTNotifyInfo = bot.CommandInfo{
+	Category:    "category_notify",
+	Description: "cmd_desc_!ytnotify",
+	Specs:       ytNotifySpecs,
+	Examples:    []string{"!ytnotify add @LinusTechTips", "!ytnotify add UCXuqSBlHAE6Xw-yeJA0Tunw New video!"},
+	Permission:  bot.PermissionServerAdmin,
+}
+
+// YTNotifyCommand manipulates YouTube channels announcer
+func YTNotifyCommand(ctx bot.Context) {
+	if !ctx.IsServerAdmin() {
+		ctx.ReplyEmbed("YouTube", ctx.Loc("admin_require"))
+		ctx.MetricsCommand("ytnotify", "error")
+		return
+	}
+	switch ctx.Arg(0) {
+	case "add":
+		ctx.MetricsCommand("ytnotify", "add")
+		args, ok := ctx.ParseArgs(ytNotifyAddArgs, 1)
+		if !ok {
+			return
+		}
+		name, err := ctx.YTNotify.AddChannel(ctx.CallContext(), ctx.Guild.ID, ctx.Message.ChannelID,
+			ctx.GetGuild().Language, args.String("channel"), args.String("message"))
+		if err != nil {
+			ctx.ReplyEmbed("YouTube", fmt.Sprintf(ctx.Loc("ytnotify_add_error"), err.Error()))
+			return
+		}
+		ctx.ReplyEmbed("YouTube", fmt.Sprintf(ctx.Loc("ytnotify_added"), name))
+	case "remove":
+		ctx.MetricsCommand("ytnotify", "remove")
+		args, ok := ctx.ParseArgs(ytNotifyRemoveArgs, 1)
+		if !ok {
+			return
+		}
+		if err := ctx.YTNotify.RemoveChannel(ctx.Guild.ID, args.String("channel")); err != nil {
+			ctx.ReplyEmbed("YouTube", ctx.Loc("ytnotify_remove_error"))
+			return
+		}
+		ctx.ReplyEmbed("YouTube", ctx.Loc("ytnotify_removed"))
+	case "list":
+		ctx.MetricsCommand("ytnotify", "list")
+		g, ok := ctx.YTNotify.Guilds[ctx.Guild.ID]
+		if !ok || len(g.Channels) == 0 {
+			ctx.ReplyEmbed("YouTube", ctx.Loc("ytnotify_list_empty"))
+			return
+		}
+		var list []string
+		for _, c := range g.Channels {
+			list = append(list, fmt.Sprintf("%v. %v (`%v`) <#%v>", len(list)+1, c.Name, c.ChannelID, c.Channel))
+		}
+		ctx.SendPages(bot.ListPages(ctx.Loc("ytnotify_list"), list, 20))
+	default:
+		ctx.ReplyEmbed("YouTube", ctx.Help(ytNotifySpecs))
+	}
+}
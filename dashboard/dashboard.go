@@ -0,0 +1,306 @@
+// Package dashboard serves web dashboard where server admins edit settings of their guilds
+package dashboard
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"gopkg.in/robfig/cron.v2"
+
+	"github.com/FlameInTheDark/dtbot/bot"
+)
+
+// Dashboard contains storages edited from dashboard, same as used by commands
+type Dashboard struct {
+	sync.Mutex
+	Conf     *bot.Config
+	Discord  *discordgo.Session
+	DB       *bot.DBWorker
+	Guilds   *bot.GuildsMap
+	Data     *bot.DataType
+	Cron     *cron.Cron
+	Twitch   *bot.Twitch
+	Sessions *bot.SessionManager
+
+	sessions map[string]*session
+	server   *http.Server
+}
+
+// guildItem guild in list of manageable guilds
+type guildItem struct {
+	ID   string
+	Name string
+}
+
+// settingItem field of settings form
+type settingItem struct {
+	Key   string
+	Name  string
+	Value string
+}
+
+// cronItem cron job of guild
+type cronItem struct {
+	ID      cron.EntryID
+	Command string
+}
+
+// Start starts dashboard server if listen address is set
+func (d *Dashboard) Start() {
+	if d.Conf.Dashboard.Listen == "" {
+		return
+	}
+	d.sessions = make(map[string]*session)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", d.handleIndex)
+	mux.HandleFunc("/login", d.handleLogin)
+	mux.HandleFunc("/callback", d.handleCallback)
+	mux.HandleFunc("/logout", d.handleLogout)
+	mux.HandleFunc("/guild/", d.handleGuild)
+	d.server = &http.Server{Addr: d.Conf.Dashboard.Listen, Handler: mux}
+	go func() {
+		if err := d.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			d.DB.Log("Dashboard", "", fmt.Sprintf("Dashboard server error: %v", err))
+			fmt.Printf("Dashboard server error: %v\n", err)
+		}
+	}()
+	fmt.Printf("Dashboard started on %v\n", d.Conf.Dashboard.Listen)
+}
+
+// checkForm returns true if form is sent by POST with CSRF token of session
+func (d *Dashboard) checkForm(r *http.Request, s *session) bool {
+	if r.Method != "POST" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(r.PostFormValue("csrf")), []byte(s.CSRF)) == 1
+}
+
+// flash saves message shown on next page of session
+func (d *Dashboard) flash(s *session, msg string) {
+	d.Lock()
+	s.Flash = msg
+	d.Unlock()
+}
+
+// takeFlash returns and clears message of session
+func (d *Dashboard) takeFlash(s *session) string {
+	d.Lock()
+	defer d.Unlock()
+	msg := s.Flash
+	s.Flash = ""
+	return msg
+}
+
+// manageableGuild returns guild if bot is in guild and user is server admin there
+func (d *Dashboard) manageableGuild(s *session, guildID string) *discordgo.Guild {
+	var member bool
+	for _, id := range s.Guilds {
+		if id == guildID {
+			member = true
+			break
+		}
+	}
+	if !member && s.User.ID != d.Conf.General.AdminID {
+		return nil
+	}
+	guild, err := d.Discord.State.Guild(guildID)
+	if err != nil {
+		return nil
+	}
+	if _, ok := d.Guilds.Get(guildID); !ok {
+		return nil
+	}
+	if !d.isGuildAdmin(s, guild) {
+		return nil
+	}
+	return guild
+}
+
+// isGuildAdmin returns true if user of session is server admin of guild, result is cached for a few minutes,
+// so pages listing many guilds do not request members from Discord API every time
+func (d *Dashboard) isGuildAdmin(s *session, guild *discordgo.Guild) bool {
+	d.Lock()
+	check, ok := s.admins[guild.ID]
+	d.Unlock()
+	if ok && time.Now().Before(check.Expires) {
+		return check.Admin
+	}
+	admin := bot.IsGuildAdmin(d.Discord, d.Conf, guild, s.User.ID)
+	d.Lock()
+	s.admins[guild.ID] = adminCheck{Admin: admin, Expires: time.Now().Add(adminCheckLifetime)}
+	d.Unlock()
+	return admin
+}
+
+// guildTextChannel returns true if channel is text channel of guild
+func guildTextChannel(guild *discordgo.Guild, channelID string) bool {
+	for _, c := range guild.Channels {
+		if c.ID == channelID && c.Type == discordgo.ChannelTypeGuildText {
+			return true
+		}
+	}
+	return false
+}
+
+// handleIndex shows login page or guilds of user
+func (d *Dashboard) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	s := d.session(r)
+	if s == nil {
+		render(w, loginTemplate, nil)
+		return
+	}
+	var guilds []guildItem
+	for _, id := range s.Guilds {
+		if g := d.manageableGuild(s, id); g != nil {
+			guilds = append(guilds, guildItem{ID: g.ID, Name: g.Name})
+		}
+	}
+	sort.Slice(guilds, func(i, j int) bool { return strings.ToLower(guilds[i].Name) < strings.ToLower(guilds[j].Name) })
+	render(w, guildsTemplate, map[string]interface{}{
+		"User":   s.User,
+		"CSRF":   s.CSRF,
+		"Guilds": guilds,
+	})
+}
+
+// handleGuild handles pages and forms of guild: /guild/{id}[/settings|/twitch/add|/twitch/remove|/cron/remove]
+func (d *Dashboard) handleGuild(w http.ResponseWriter, r *http.Request) {
+	s := d.session(r)
+	if s == nil {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/guild/"), "/", 2)
+	guild := d.manageableGuild(s, parts[0])
+	if guild == nil {
+		http.Error(w, "Guild not found or you are not server admin", http.StatusForbidden)
+		return
+	}
+	if len(parts) == 1 {
+		d.showGuild(w, s, guild)
+		return
+	}
+	if !d.checkForm(r, s) {
+		http.Error(w, "Wrong form token", http.StatusForbidden)
+		return
+	}
+	switch parts[1] {
+	case "settings":
+		d.flash(s, d.saveSettings(r, guild))
+	case "twitch/add":
+		channel := r.PostFormValue("channel")
+		if !guildTextChannel(guild, channel) {
+			http.Error(w, "Channel not found", http.StatusBadRequest)
+			return
+		}
+		name, err := d.Twitch.AddStreamer(r.Context(), guild.ID, channel,
+			strings.ToLower(strings.TrimSpace(r.PostFormValue("login"))), strings.TrimSpace(r.PostFormValue("message")))
+		if err != nil {
+			d.flash(s, fmt.Sprintf("Error adding streamer: %v", err))
+		} else {
+			d.flash(s, fmt.Sprintf("Streamer %v added", name))
+		}
+	case "twitch/remove":
+		if err := d.Twitch.RemoveStreamer(r.PostFormValue("login"), guild.ID); err != nil {
+			d.flash(s, fmt.Sprintf("Error removing streamer: %v", err))
+		} else {
+			d.flash(s, "Streamer removed")
+		}
+	case "cron/remove":
+		id, err := strconv.Atoi(r.PostFormValue("id"))
+		if err == nil {
+			err = d.Data.RemoveGuildCronJob(d.Cron, guild.ID, cron.EntryID(id))
+		}
+		if err != nil {
+			d.flash(s, fmt.Sprintf("Error removing job: %v", err))
+		} else {
+			d.flash(s, "Job removed")
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	d.DB.Log("Dashboard", guild.ID, fmt.Sprintf("User [%v] changed %v", s.User.ID, parts[1]))
+	http.Redirect(w, r, "/guild/"+guild.ID, http.StatusSeeOther)
+}
+
+// saveSettings saves changed settings of guild and returns result message
+func (d *Dashboard) saveSettings(r *http.Request, guild *discordgo.Guild) string {
+	data, _ := d.Guilds.Get(guild.ID)
+	var errs []string
+	for _, key := range bot.GuildSettingKeys {
+		value := r.PostFormValue(key)
+		if value == data.Setting(key) {
+			continue
+		}
+		if err := d.DB.SetGuildSetting(d.Conf, d.Guilds, guild.ID, key, value); err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", bot.GuildSettingNames[key], err))
+			continue
+		}
+		if key == bot.SettingVoiceVolume {
+			if sess := d.Sessions.GetByGuild(guild.ID); sess != nil {
+				if updated, ok := d.Guilds.Get(guild.ID); ok {
+					sess.SetVolume(updated.VoiceVolume)
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return "Not saved: " + strings.Join(errs, "; ")
+	}
+	return "Settings saved"
+}
+
+// showGuild shows settings, streamers and cron jobs of guild
+func (d *Dashboard) showGuild(w http.ResponseWriter, s *session, guild *discordgo.Guild) {
+	data, _ := d.Guilds.Get(guild.ID)
+	var settings []settingItem
+	for _, key := range bot.GuildSettingKeys {
+		settings = append(settings, settingItem{Key: key, Name: bot.GuildSettingNames[key], Value: data.Setting(key)})
+	}
+	var languages []string
+	for lang := range d.Conf.Locales {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	var channels = make(map[string]string)
+	var textChannels []*discordgo.Channel
+	for _, c := range guild.Channels {
+		if c.Type == discordgo.ChannelTypeGuildText {
+			channels[c.ID] = c.Name
+			textChannels = append(textChannels, c)
+		}
+	}
+	sort.Slice(textChannels, func(i, j int) bool { return textChannels[i].Position < textChannels[j].Position })
+
+	var jobs []cronItem
+	for id, cmd := range d.Data.GuildCronJobs(guild.ID) {
+		jobs = append(jobs, cronItem{ID: id, Command: cmd})
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+
+	render(w, guildTemplate, map[string]interface{}{
+		"User":         s.User,
+		"CSRF":         s.CSRF,
+		"Flash":        d.takeFlash(s),
+		"Guild":        guildItem{ID: guild.ID, Name: guild.Name},
+		"Settings":     settings,
+		"Languages":    languages,
+		"Channels":     textChannels,
+		"ChannelNames": channels,
+		"Streams":      d.Twitch.GuildStreams(guild.ID),
+		"Jobs":         jobs,
+	})
+}
@@ -0,0 +1,227 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+)
+
+const (
+	discordAPIURL = "https://discord.com/api/"
+	// sessionCookie name of cookie with session token
+	sessionCookie = "dashboard_session"
+	// stateCookie name of cookie with OAuth2 state
+	stateCookie = "dashboard_state"
+	// sessionLifetime lifetime of dashboard sessions
+	sessionLifetime = 24 * time.Hour
+	// adminCheckLifetime lifetime of cached checks of server admin rights
+	adminCheckLifetime = 5 * time.Minute
+)
+
+// discordUser contains response of Discord API for current user
+type discordUser struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Discriminator string `json:"discriminator"`
+}
+
+// discordGuild contains response of Discord API for guilds of current user
+type discordGuild struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// discordToken contains response of Discord OAuth2 token endpoint
+type discordToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+}
+
+// session contains logged in user
+type session struct {
+	User discordUser
+	// Guilds IDs of guilds where user is member
+	Guilds []string
+	// CSRF token of forms
+	CSRF    string
+	Expires time.Time
+	// Flash message shown on next page
+	Flash string
+	// admins cached checks of server admin rights by guild ID
+	admins map[string]adminCheck
+}
+
+// adminCheck cached check of server admin rights
+type adminCheck struct {
+	Admin   bool
+	Expires time.Time
+}
+
+// randomToken returns random hex string
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// redirectURL returns OAuth2 redirect address of dashboard
+func (d *Dashboard) redirectURL() string {
+	return strings.TrimSuffix(d.Conf.Dashboard.URL, "/") + "/callback"
+}
+
+// secure returns true if dashboard is served over HTTPS, cookies are sent only over HTTPS then
+func (d *Dashboard) secure() bool {
+	return strings.HasPrefix(d.Conf.Dashboard.URL, "https://")
+}
+
+// session returns session of request, nil if user is not logged in
+func (d *Dashboard) session(r *http.Request) *session {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+	d.Lock()
+	defer d.Unlock()
+	for token, s := range d.sessions {
+		if time.Now().After(s.Expires) {
+			delete(d.sessions, token)
+		}
+	}
+	return d.sessions[cookie.Value]
+}
+
+// handleLogin redirects user to Discord authorization
+func (d *Dashboard) handleLogin(w http.ResponseWriter, r *http.Request) {
+	state := randomToken()
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   d.secure(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	query := url.Values{
+		"client_id":     {d.Conf.Dashboard.ClientID},
+		"redirect_uri":  {d.redirectURL()},
+		"response_type": {"code"},
+		"scope":         {"identify guilds"},
+		"state":         {state},
+	}
+	http.Redirect(w, r, discordAPIURL+"oauth2/authorize?"+query.Encode(), http.StatusFound)
+}
+
+// handleCallback exchanges authorization code and creates session
+func (d *Dashboard) handleCallback(w http.ResponseWriter, r *http.Request) {
+	state, err := r.Cookie(stateCookie)
+	if err != nil || state.Value == "" || state.Value != r.URL.Query().Get("state") {
+		http.Error(w, "Wrong OAuth2 state", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: "/", MaxAge: -1})
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	token, err := d.exchangeCode(r.Context(), code)
+	if err != nil {
+		d.DB.Log("Dashboard", "", "OAuth2 token error: "+err.Error())
+		http.Error(w, "Discord authorization error", http.StatusBadGateway)
+		return
+	}
+	var user discordUser
+	if err := discordGet(r.Context(), token, "users/@me", &user); err != nil {
+		d.DB.Log("Dashboard", "", "Getting user error: "+err.Error())
+		http.Error(w, "Discord authorization error", http.StatusBadGateway)
+		return
+	}
+	var guilds []discordGuild
+	if err := discordGet(r.Context(), token, "users/@me/guilds", &guilds); err != nil {
+		d.DB.Log("Dashboard", "", "Getting user guilds error: "+err.Error())
+		http.Error(w, "Discord authorization error", http.StatusBadGateway)
+		return
+	}
+
+	s := &session{
+		User:    user,
+		CSRF:    randomToken(),
+		Expires: time.Now().Add(sessionLifetime),
+		admins:  make(map[string]adminCheck),
+	}
+	for _, g := range guilds {
+		s.Guilds = append(s.Guilds, g.ID)
+	}
+	id := randomToken()
+	d.Lock()
+	d.sessions[id] = s
+	d.Unlock()
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id,
+		Path:     "/",
+		Expires:  s.Expires,
+		HttpOnly: true,
+		Secure:   d.secure(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// handleLogout removes session
+func (d *Dashboard) handleLogout(w http.ResponseWriter, r *http.Request) {
+	s := d.session(r)
+	if s == nil || !d.checkForm(r, s) {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+	cookie, _ := r.Cookie(sessionCookie)
+	d.Lock()
+	delete(d.sessions, cookie.Value)
+	d.Unlock()
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// exchangeCode returns access token of user by authorization code
+func (d *Dashboard) exchangeCode(ctx context.Context, code string) (string, error) {
+	form := url.Values{
+		"client_id":     {d.Conf.Dashboard.ClientID},
+		"client_secret": {d.Conf.Dashboard.ClientSecret},
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {d.redirectURL()},
+	}
+	body, err := httpclient.For("discord").Post(ctx, discordAPIURL+"oauth2/token", "application/x-www-form-urlencoded", []byte(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	var token discordToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// discordGet sends Discord API request with user access token, responses are not cached
+func discordGet(ctx context.Context, token, method string, result interface{}) error {
+	req, err := http.NewRequest("GET", discordAPIURL+method, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	body, err := httpclient.For("discord").Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, result)
+}
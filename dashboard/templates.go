@@ -0,0 +1,97 @@
+package dashboard
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+)
+
+const layout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>DTBot dashboard</title>
+<style>
+body { font-family: sans-serif; background: #2f3136; color: #dcddde; max-width: 900px; margin: 20px auto; }
+a { color: #00b0f4; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 20px; }
+td, th { padding: 6px; text-align: left; border-bottom: 1px solid #40444b; }
+input, select, textarea { background: #40444b; color: #dcddde; border: 0; padding: 6px; width: 100%; box-sizing: border-box; }
+button { background: #5865f2; color: #fff; border: 0; padding: 6px 14px; cursor: pointer; }
+.flash { background: #40444b; padding: 10px; margin-bottom: 20px; }
+.header { display: flex; justify-content: space-between; align-items: center; }
+</style>
+</head>
+<body>
+{{template "content" .}}
+</body>
+</html>`
+
+const header = `{{define "header"}}<div class="header">
+<h2><a href="/">DTBot</a></h2>
+<form method="post" action="/logout">{{.User.Username}} <input type="hidden" name="csrf" value="{{.CSRF}}"><button>Logout</button></form>
+</div>{{end}}`
+
+var loginTemplate = template.Must(template.New("login").Parse(layout + `{{define "content"}}
+<h2>DTBot</h2>
+<p>Log in with Discord to edit settings of guilds where you are server admin.</p>
+<p><a href="/login">Login with Discord</a></p>
+{{end}}`))
+
+var guildsTemplate = template.Must(template.New("guilds").Parse(layout + header + `{{define "content"}}
+{{template "header" .}}
+<h3>Guilds</h3>
+{{if .Guilds}}<table>{{range .Guilds}}<tr><td><a href="/guild/{{.ID}}">{{.Name}}</a></td></tr>{{end}}</table>
+{{else}}<p>No guilds where you are server admin. Server owner, members with "bot.admin" role and bot admin can edit settings.</p>{{end}}
+{{end}}`))
+
+var guildTemplate = template.Must(template.New("guild").Parse(layout + header + `{{define "content"}}
+{{template "header" .}}
+<h3>{{.Guild.Name}}</h3>
+{{if .Flash}}<div class="flash">{{.Flash}}</div>{{end}}
+
+<h4>Settings</h4>
+<form method="post" action="/guild/{{.Guild.ID}}/settings">
+<input type="hidden" name="csrf" value="{{.CSRF}}">
+<table>
+{{$languages := .Languages}}
+{{range .Settings}}<tr><td>{{.Name}}</td><td>
+{{if eq .Key "general.language"}}{{$value := .Value}}<select name="{{.Key}}">{{range $languages}}<option value="{{.}}"{{if eq . $value}} selected{{end}}>{{.}}</option>{{end}}</select>
+{{else if eq .Key "greeting"}}<textarea name="{{.Key}}" rows="3">{{.Value}}</textarea>
+{{else if eq .Key "embed.color"}}<input type="color" name="{{.Key}}" value="{{.Value}}">
+{{else if eq .Key "general.timezone"}}<input type="number" name="{{.Key}}" min="-12" max="14" value="{{.Value}}">
+{{else if eq .Key "voice.volume"}}<input type="number" name="{{.Key}}" min="0" step="any" value="{{.Value}}">
+{{else}}<input type="text" name="{{.Key}}" value="{{.Value}}">{{end}}
+</td></tr>{{end}}
+</table>
+<button>Save</button>
+</form>
+
+<h4>Twitch streamers</h4>
+<table>
+<tr><th>Streamer</th><th>Channel</th><th>Message</th><th></th></tr>
+{{$csrf := .CSRF}}{{$guild := .Guild.ID}}{{$names := .ChannelNames}}
+{{range .Streams}}<tr><td><a href="https://twitch.tv/{{.Login}}">{{.Name}}</a></td><td>#{{index $names .Channel}}</td><td>{{.CustomMessage}}</td>
+<td><form method="post" action="/guild/{{$guild}}/twitch/remove"><input type="hidden" name="csrf" value="{{$csrf}}"><input type="hidden" name="login" value="{{.Login}}"><button>Remove</button></form></td></tr>{{end}}
+<tr><td><input type="text" name="login" form="twitch-add" placeholder="Login" required></td>
+<td><select name="channel" form="twitch-add">{{range .Channels}}<option value="{{.ID}}">#{{.Name}}</option>{{end}}</select></td>
+<td><input type="text" name="message" form="twitch-add" placeholder="Custom message"></td>
+<td><button form="twitch-add">Add</button></td></tr>
+</table>
+<form id="twitch-add" method="post" action="/guild/{{.Guild.ID}}/twitch/add"><input type="hidden" name="csrf" value="{{.CSRF}}"></form>
+
+<h4>Cron jobs</h4>
+<p>Jobs are added by <code>!cron add</code> command in channel where they reply, here they can be removed.</p>
+{{if .Jobs}}<table>
+{{range .Jobs}}<tr><td>{{.ID}}</td><td>{{.Command}}</td>
+<td><form method="post" action="/guild/{{$guild}}/cron/remove"><input type="hidden" name="csrf" value="{{$csrf}}"><input type="hidden" name="id" value="{{.ID}}"><button>Remove</button></form></td></tr>{{end}}
+</table>{{else}}<p>Schedule is empty</p>{{end}}
+{{end}}`))
+
+// render executes template, errors are sent as plain text
+func render(w http.ResponseWriter, t *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := t.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("Template error: %v", err), http.StatusInternalServerError)
+	}
+}
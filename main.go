@@ -1,7 +1,7 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -13,8 +13,15 @@ import (
 
 	"gopkg.in/robfig/cron.v2"
 
+	"github.com/FlameInTheDark/dtbot/adminapi"
+	"github.com/FlameInTheDark/dtbot/api/currency"
+	"github.com/FlameInTheDark/dtbot/api/geocoding"
+	"github.com/FlameInTheDark/dtbot/api/httpclient"
+	"github.com/FlameInTheDark/dtbot/api/news"
+	"github.com/FlameInTheDark/dtbot/api/translate"
 	"github.com/FlameInTheDark/dtbot/bot"
 	"github.com/FlameInTheDark/dtbot/cmd"
+	"github.com/FlameInTheDark/dtbot/dashboard"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -32,8 +39,10 @@ var (
 	guilds          *bot.GuildsMap
 	botCron         *cron.Cron
 	twitch          *bot.Twitch
+	ytNotify        *bot.YoutubeNotify
 	albUpdater      *bot.AlbionUpdater
 	blacklist       *bot.BlackListStruct
+	geocoder        *geocoding.Service
 	messagesCounter int
 )
 
@@ -79,33 +88,85 @@ func main() {
 	defer botCron.Stop()
 	defer dbWorker.DBSession.Close()
 	twitch = bot.TwitchInit(discord, conf, dbWorker)
-	albUpdater = bot.AlbionGetUpdater(dbWorker)
+	ytNotify = bot.YoutubeNotifyInit(discord, conf, dbWorker)
+	albUpdater = bot.AlbionGetUpdater(discord, conf, dbWorker)
 	blacklist = dbWorker.GetBlacklist()
+	geocoder = bot.NewGeocoder(conf, dbWorker)
+	bot.InitCache(conf, dbWorker)
 	go BotUpdater(discord)
+	scheduler := bot.NewNotifyScheduler(twitch.Notifier, ytNotify.Notifier, albUpdater.PlayerNotifier, albUpdater.GuildNotifier)
+	scheduler.Add(news.FeedNotifier(discord, dbWorker, guilds, conf))
+	go scheduler.Run()
+	dash := &dashboard.Dashboard{
+		Conf:     conf,
+		Discord:  discord,
+		DB:       dbWorker,
+		Guilds:   guilds,
+		Data:     dataType,
+		Cron:     botCron,
+		Twitch:   twitch,
+		Sessions: Sessions,
+	}
+	dash.Start()
+	api := &adminapi.API{
+		Conf:      conf,
+		Discord:   discord,
+		DB:        dbWorker,
+		Sessions:  Sessions,
+		BlackList: blacklist,
+	}
+	api.Start()
 	// Init command handler
 	discord.AddHandler(guildAddHandler)
 	discord.AddHandler(commandHandler)
 	discord.AddHandler(joinHandler)
+	discord.AddHandler(autoTranslateHandler)
+	discord.AddHandler(reactionTranslateHandler)
 	onStart()
 	<-sc
 }
 
 // Handle new users
 func joinHandler(discord *discordgo.Session, e *discordgo.GuildMemberAdd) {
-	if _, ok := guilds.Guilds[e.GuildID]; !ok {
+	if g, ok := guilds.Get(e.GuildID); !ok {
 		dbWorker.InitNewGuild(e.GuildID, conf, guilds)
 	} else {
-		bot.Greetings(discord, e, guilds.Guilds[e.GuildID])
+		bot.Greetings(discord, e, g)
+	}
+}
+
+// Handle messages in channels with automatic translation
+func autoTranslateHandler(discord *discordgo.Session, message *discordgo.MessageCreate) {
+	if message.Author == nil || message.Author.ID == botId || strings.HasPrefix(message.Content, "!") {
+		return
+	}
+	if blacklist.CheckGuild(message.GuildID) || blacklist.CheckUser(message.Author.ID) {
+		return
+	}
+	if g, ok := guilds.Get(message.GuildID); ok && len(g.AutoTranslate) > 0 {
+		translate.AutoTranslate(discord, message, g, conf)
+	}
+}
+
+// Handle flag reactions for translation
+func reactionTranslateHandler(discord *discordgo.Session, reaction *discordgo.MessageReactionAdd) {
+	if reaction.UserID == botId || blacklist.CheckGuild(reaction.GuildID) || blacklist.CheckUser(reaction.UserID) {
+		return
+	}
+	if g, ok := guilds.Get(reaction.GuildID); ok && g.ReactionTranslate {
+		translate.ReactionTranslate(discord, reaction, g, conf)
 	}
 }
 
 // Handle new guilds
 func guildAddHandler(discord *discordgo.Session, e *discordgo.GuildCreate) {
-	if _, ok := guilds.Guilds[e.ID]; !ok {
+	g, ok := guilds.Get(e.ID)
+	if !ok {
 		dbWorker.InitNewGuild(e.ID, conf, guilds)
+		g, _ = guilds.Get(e.ID)
 	}
 	emb := bot.NewEmbed("").
-		Field(conf.GetLocaleLang("bot_joined_title", guilds.Guilds[e.ID].Language), conf.GetLocaleLang("bot_joined_text", guilds.Guilds[e.ID].Language), false)
+		Field(conf.GetLocaleLang("bot_joined_title", g.Language), conf.GetLocaleLang("bot_joined_text", g.Language), false)
 	_, _ = discord.ChannelMessageSendEmbed(e.OwnerID, emb.GetEmbed())
 }
 
@@ -170,51 +231,51 @@ func commandHandler(discord *discordgo.Session, message *discordgo.MessageCreate
 			guilds,
 			botCron,
 			twitch,
+			ytNotify,
 			albUpdater,
-			blacklist)
+			blacklist,
+			geocoder)
 		ctx.Args = args[1:]
-		c := *command
-		c(*ctx)
+		ctx.RunCommand(name, *command)
 	} else {
 		dbWorker.Log("Message", guild.ID, msg)
 		query := []byte(fmt.Sprintf("logs,server=%v module=\"%v\"", guild.ID, "message"))
 		addr := fmt.Sprintf("%v/write?db=%v", conf.Metrics.Address, conf.Metrics.Database)
-		r := bytes.NewReader(query)
-		_, _ = http.Post(addr, "", r)
+		_, _ = httpclient.For("metrics").Post(context.Background(), addr, "", query)
 	}
 }
 
 // Adds bot commands
 func registerCommands() {
-	CmdHandler.Register("!r", cmd.RadioCommand)
-	CmdHandler.Register("!w", cmd.WeatherCommand)
-	CmdHandler.Register("!t", cmd.TranslateCommand)
-	CmdHandler.Register("!n", cmd.NewsCommand)
-	CmdHandler.Register("!c", cmd.CurrencyCommand)
-	CmdHandler.Register("!y", cmd.YoutubeCommand)
-	CmdHandler.Register("!v", cmd.VoiceCommand)
-	CmdHandler.Register("!b", cmd.BotCommand)
-	CmdHandler.Register("!play", cmd.YoutubeShortCommand)
-	CmdHandler.Register("!d", cmd.DebugCommand)
-	CmdHandler.Register("!p", cmd.PollCommand)
-	CmdHandler.Register("!m", cmd.YandexmapCommand)
-	CmdHandler.Register("!dice", cmd.DiceCommand)
-	CmdHandler.Register("!help", cmd.HelpCommand)
-	CmdHandler.Register("!cron", cmd.CronCommand)
-	CmdHandler.Register("!geoip", cmd.GeoIPCommand)
-	CmdHandler.Register("!twitch", cmd.TwitchCommand)
-	CmdHandler.Register("!greetings", cmd.GreetingsCommand)
-	CmdHandler.Register("!alb", cmd.AlbionCommand)
-	CmdHandler.Register("!slap", cmd.SlapCommand)
-	CmdHandler.Register("!fu", cmd.FUCommand)
+	CmdHandler.Register("!r", cmd.RadioCommand, cmd.RadioInfo)
+	CmdHandler.Register("!w", cmd.WeatherCommand, cmd.WeatherInfo)
+	CmdHandler.Register("!t", cmd.TranslateCommand, cmd.TranslateInfo)
+	CmdHandler.Register("!n", cmd.NewsCommand, cmd.NewsInfo)
+	CmdHandler.Register("!c", cmd.CurrencyCommand, cmd.CurrencyInfo)
+	CmdHandler.Register("!y", cmd.YoutubeCommand, cmd.YoutubeInfo)
+	CmdHandler.Register("!v", cmd.VoiceCommand, cmd.VoiceInfo)
+	CmdHandler.Register("!b", cmd.BotCommand, cmd.BotInfo)
+	CmdHandler.Register("!play", cmd.YoutubeShortCommand, cmd.YoutubeShortInfo)
+	CmdHandler.Register("!d", cmd.DebugCommand, cmd.DebugInfo)
+	CmdHandler.Register("!p", cmd.PollCommand, cmd.PollInfo)
+	CmdHandler.Register("!m", cmd.YandexmapCommand, cmd.YandexmapInfo)
+	CmdHandler.Register("!dice", cmd.DiceCommand, cmd.DiceInfo)
+	CmdHandler.Register("!help", cmd.HelpCommand, cmd.HelpInfo)
+	CmdHandler.Register("!cron", cmd.CronCommand, cmd.CronInfo)
+	CmdHandler.Register("!geoip", cmd.GeoIPCommand, cmd.GeoIPInfo)
+	CmdHandler.Register("!twitch", cmd.TwitchCommand, cmd.TwitchInfo)
+	CmdHandler.Register("!ytnotify", cmd.YTNotifyCommand, cmd.YTNotifyInfo)
+	CmdHandler.Register("!greetings", cmd.GreetingsCommand, cmd.GreetingsInfo)
+	CmdHandler.Register("!alb", cmd.AlbionCommand, cmd.AlbionInfo)
+	CmdHandler.Register("!slap", cmd.SlapCommand, cmd.SlapInfo)
+	CmdHandler.Register("!fu", cmd.FUCommand, cmd.FUInfo)
 }
 
 // MetricsSender sends metrics to InfluxDB and another services
 func BotUpdater(d *discordgo.Session) {
 	for {
 		var vregions = make(map[string]int)
-		go twitch.Update()
-		go albUpdater.Update(d, dbWorker, conf)
+		go currency.CheckAlerts(d, dbWorker, conf)
 		// Calculating users count
 		usersCount := 0
 		for _, g := range d.State.Guilds {
@@ -227,16 +288,14 @@ func BotUpdater(d *discordgo.Session) {
 		queryCounters := []byte(fmt.Sprintf("counters guilds=%d,messages=%d,users=%d,voices=%d", len(d.State.Guilds), messagesCounter, usersCount, Sessions.Count()))
 		addrCounters := fmt.Sprintf("%v/write?db=%v&u=%v&p=%v",
 			conf.Metrics.Address, conf.Metrics.Database, conf.Metrics.User, conf.Metrics.Password)
-		rCounters := bytes.NewReader(queryCounters)
-		_, _ = http.Post(addrCounters, "", rCounters)
+		_, _ = httpclient.For("metrics").Post(context.Background(), addrCounters, "", queryCounters)
 
 		// Voice region metrics
 		for r, c := range vregions {
 			queryCounters := []byte(fmt.Sprintf("region_%s count=%d", r, c))
 			addrCounters := fmt.Sprintf("%v/write?db=%v&u=%v&p=%v",
 				conf.Metrics.Address, conf.Metrics.Database, conf.Metrics.User, conf.Metrics.Password)
-			rCounters := bytes.NewReader(queryCounters)
-			_, _ = http.Post(addrCounters, "", rCounters)
+			_, _ = httpclient.For("metrics").Post(context.Background(), addrCounters, "", queryCounters)
 		}
 
 		// Bot lists
@@ -249,10 +308,6 @@ func BotUpdater(d *discordgo.Session) {
 }
 
 func sendDBL(botID, token string, guilds int) {
-	timeout := time.Duration(time.Duration(1) * time.Second)
-	client := &http.Client{
-		Timeout: time.Duration(timeout),
-	}
 	query := url.Values{}
 	query.Add("server_count", fmt.Sprintf("%v", guilds))
 	req, _ := http.NewRequest("POST",
@@ -260,13 +315,12 @@ func sendDBL(botID, token string, guilds int) {
 			botID), strings.NewReader(query.Encode()))
 	req.Header.Add("Authorization", token)
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	_, _ = client.Do(req)
+	_, _ = httpclient.For("dbl").Do(context.Background(), req)
 }
 
 func onStart() {
 	queryCounters := []byte(fmt.Sprintf("starts value=1"))
 	addrCounters := fmt.Sprintf("%v/write?db=%v&u=%v&p=%v",
 		conf.Metrics.Address, conf.Metrics.Database, conf.Metrics.User, conf.Metrics.Password)
-	rCounters := bytes.NewReader(queryCounters)
-	_, _ = http.Post(addrCounters, "", rCounters)
+	_, _ = httpclient.For("metrics").Post(context.Background(), addrCounters, "", queryCounters)
 }